@@ -0,0 +1,32 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "errors"
+
+// ErrSeekAborted is returned by a seek that was stopped by user input
+// before it found a station.
+var ErrSeekAborted = errors.New("si4703: seek aborted")
+
+// ErrSeekFailed is returned by a seek that reached the band limit without
+// finding a station. The chip is left on the channel it stopped at.
+var ErrSeekFailed = errors.New("si4703: seek found no station")
+
+// SetAbortInput makes user input abort a seek or scan in progress: as soon
+// as a value arrives on input the seek is stopped where it is and returns
+// ErrSeekAborted, and ScanPreview, SeekTP and SeekPTY return on the
+// station reached. Feed it from the handler of a tuning knob or button
+// with a non-blocking send so the radio responds mid way through a full
+// band seek. Pass nil to stop listening.
+func (d *Device) SetAbortInput(input <-chan struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.abort = input
+}
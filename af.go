@@ -0,0 +1,81 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// AF codes carried in block C of group 0A, two per group
+const (
+	afFiller    = 205
+	afCountBase = 224 // 224 to 249 start a list of 0 to 25 frequencies
+	afCountLast = 249
+	afLFMF      = 250 // the next code is an LF/MF frequency
+)
+
+// maxAFs is the most alternative frequencies a station can list.
+const maxAFs = 25
+
+// afDecoder accumulates the alternative frequencies of group 0A, sent
+// either as one list (method A) or as lists of pairs each naming the
+// transmitter the list belongs to (method B).
+type afDecoder struct {
+	// frequency heading the list being received
+	head uint32
+	list []uint32
+}
+
+// afFrequency converts a VHF AF code to kHz, 0 for other codes.
+func afFrequency(code byte) uint32 {
+	if code == 0 || code >= afFiller {
+		return 0
+	}
+	return 87500 + uint32(code)*100
+}
+
+// update decodes the two codes of block C of a group 0A received on tuned.
+func (a *afDecoder) update(c uint16, tuned uint32) {
+	c1, c2 := byte(c>>8), byte(c)
+	if c1 >= afCountBase && c1 <= afCountLast {
+		a.head = afFrequency(c2)
+		a.add(a.head, tuned)
+		return
+	}
+	if c1 == afLFMF || c2 == afLFMF {
+		// LF/MF frequencies are out of reach of the tuner
+		return
+	}
+	f1, f2 := afFrequency(c1), afFrequency(c2)
+	switch {
+	case a.head != 0 && (f1 == a.head || f2 == a.head):
+		// method B: one of the pair is the transmitter the list is for,
+		// a descending pair marks a regional variant of the programme
+		if a.head != tuned || f1 > f2 {
+			return
+		}
+		if f1 == a.head {
+			a.add(f2, tuned)
+		} else {
+			a.add(f1, tuned)
+		}
+	default:
+		a.add(f1, tuned)
+		a.add(f2, tuned)
+	}
+}
+
+func (a *afDecoder) add(f, tuned uint32) {
+	if f == 0 || f == tuned || len(a.list) == maxAFs {
+		return
+	}
+	for _, known := range a.list {
+		if known == f {
+			return
+		}
+	}
+	a.list = append(a.list, f)
+}
@@ -0,0 +1,176 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package alert makes a muted or quiet radio speak up for emergencies. While
+// enabled it watches the RDS of the tuned station and, when the station
+// signals an alarm (programme type 31) or a traffic announcement within the
+// configured hours, unmutes the tuner and raises the volume. The previous
+// mute and volume settings are restored when the announcement ends.
+//
+//	m := alert.New(&fm, alert.Config{Volume: 12, Alarm: true, Traffic: true, From: 6, To: 23})
+//	m.OnEvent = func(e alert.Event) { ... }
+//	m.Run()
+package alert
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// PTYAlarm is the RDS programme type used for emergency announcements.
+const PTYAlarm = 31
+
+// Config selects what triggers an alert and how loud it plays.
+type Config struct {
+	// Volume is the level the tuner is raised to during an alert.
+	Volume uint16
+	// Alarm triggers on programme type 31.
+	Alarm bool
+	// Traffic triggers on traffic announcements.
+	Traffic bool
+	// From and To are the hours of the day, 0 to 24, during which alerts
+	// are acted on. The range may wrap past midnight; equal values mean
+	// all day.
+	From, To int
+}
+
+type Reason uint8
+
+const (
+	ReasonAlarm Reason = iota
+	ReasonTraffic
+)
+
+// Event reports that an alert started (Active) or ended.
+type Event struct {
+	Active bool
+	Reason Reason
+	Time   time.Time
+	RDS    si4703.RDSData
+}
+
+// Monitor watches for alerts on one device.
+type Monitor struct {
+	OnEvent func(Event)
+
+	cfg    Config
+	dev    *si4703.Device
+	active bool
+	reason Reason
+	volume uint8 // on the extended scale, so VOLEXT is restored too
+	muted  bool
+}
+
+func New(dev *si4703.Device, cfg Config) *Monitor {
+	return &Monitor{
+		cfg: cfg,
+		dev: dev,
+	}
+}
+
+// Active reports whether an alert is currently playing.
+func (m *Monitor) Active() bool {
+	return m.active
+}
+
+// Run reads RDS and checks for alerts after every group until the device
+// fails.
+func (m *Monitor) Run() error {
+	return m.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (m *Monitor) RunContext(ctx context.Context) error {
+	for {
+		_, ok, err := m.dev.ReadRDS()
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := m.Check(time.Now()); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.dev.Timings().RDSPoll):
+		}
+	}
+}
+
+// Check compares the decoded RDS with the alert conditions at time now,
+// starting or ending an alert as needed. Use it instead of Run when the
+// application reads RDS itself.
+func (m *Monitor) Check(now time.Time) error {
+	data := m.dev.RDSData()
+	reason, alarm := m.triggered(data)
+	if alarm && !m.active && m.inHours(now) {
+		volume, err := m.dev.VolumeExtended()
+		if err != nil {
+			return err
+		}
+		muted, err := m.dev.Muted()
+		if err != nil {
+			return err
+		}
+		if err := m.dev.SetVolume(m.cfg.Volume); err != nil {
+			return err
+		}
+		if err := m.dev.DisableMute(); err != nil {
+			return err
+		}
+		m.volume, m.muted = volume, muted
+		m.active = true
+		m.reason = reason
+		m.emit(Event{Active: true, Reason: reason, Time: now, RDS: data})
+	} else if !alarm && m.active {
+		if err := m.dev.SetVolumeExtended(m.volume); err != nil {
+			return err
+		}
+		if m.muted {
+			if err := m.dev.EnableMute(); err != nil {
+				return err
+			}
+		}
+		m.active = false
+		m.emit(Event{Active: false, Reason: m.reason, Time: now, RDS: data})
+	}
+	return nil
+}
+
+func (m *Monitor) triggered(data si4703.RDSData) (Reason, bool) {
+	if m.cfg.Alarm && data.ProgramType == PTYAlarm {
+		return ReasonAlarm, true
+	}
+	if m.cfg.Traffic && data.TrafficProgram && data.TrafficAnnouncement {
+		return ReasonTraffic, true
+	}
+	return 0, false
+}
+
+func (m *Monitor) inHours(now time.Time) bool {
+	from, to, hour := m.cfg.From, m.cfg.To, now.Hour()
+	switch {
+	case from == to:
+		return true
+	case from < to:
+		return hour >= from && hour < to
+	default:
+		return hour >= from || hour < to
+	}
+}
+
+func (m *Monitor) emit(e Event) {
+	if m.OnEvent != nil {
+		m.OnEvent(e)
+	}
+}
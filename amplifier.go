@@ -0,0 +1,78 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// AmpHooks are called around operations that make the tuner output pop or
+// hiss, so an external amplifier can be muted or put in standby. Any hook
+// may be nil.
+//
+// PreTune runs before a tune or seek starts and PostTune once it has
+// completed. OnMute runs with true before the chip is muted or powered up
+// or down, and with false after it has been unmuted.
+type AmpHooks struct {
+	PreTune  func()
+	PostTune func()
+	OnMute   func(muted bool)
+}
+
+// SetAmpHooks installs the amplifier hooks.
+func (d *Device) SetAmpHooks(hooks AmpHooks) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.amp = hooks
+}
+
+// AmpPin returns hooks that drive an amplifier mute or standby pin. The pin
+// is set to muteLevel while the amplifier should be silent. The amplifier
+// stays muted after a tune if the tuner itself is muted.
+func AmpPin(pin Pin, muteLevel bool) AmpHooks {
+	configureOutput(pin)
+	set := func(level bool) {
+		if level {
+			pin.High()
+		} else {
+			pin.Low()
+		}
+	}
+	set(muteLevel)
+	muted := true
+	return AmpHooks{
+		PreTune: func() {
+			set(muteLevel)
+		},
+		PostTune: func() {
+			if !muted {
+				set(!muteLevel)
+			}
+		},
+		OnMute: func(m bool) {
+			muted = m
+			set(m == muteLevel)
+		},
+	}
+}
+
+func (d *Device) preTune() {
+	if d.amp.PreTune != nil {
+		d.amp.PreTune()
+	}
+}
+
+func (d *Device) postTune() {
+	if d.amp.PostTune != nil {
+		d.amp.PostTune()
+	}
+}
+
+func (d *Device) onMute(muted bool) {
+	if d.amp.OnMute != nil {
+		d.amp.OnMute(muted)
+	}
+}
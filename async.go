@@ -0,0 +1,150 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrBusy is returned when a seek or tune is started while the one started
+// by StartSeek or StartTune is still in progress, and by ScanPreview while
+// another preview is running.
+var ErrBusy = errors.New("si4703: seek, tune or scan in progress")
+
+// pendingOp is a seek or tune running in the background.
+type pendingOp struct {
+	kind    uint8
+	dir     byte
+	since   time.Time
+	retries int
+}
+
+const (
+	pendingNone uint8 = iota
+	pendingSeek
+	pendingTune
+)
+
+// StartSeek starts a seek in dir like Seek but returns without waiting for
+// it, so the application can keep updating its display while the chip
+// hunts for a station. Poll SeekResult until it reports the seek done.
+// Other tuning methods must not be called meanwhile.
+func (d *Device) StartSeek(dir byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending.kind != pendingNone {
+		return ErrBusy
+	}
+	if err := d.startSeek(dir); err != nil {
+		return d.registerError("seek", err)
+	}
+	d.pending = pendingOp{kind: pendingSeek, dir: dir, since: time.Now()}
+	return nil
+}
+
+// SeekInProgress reports whether a seek started by StartSeek has yet to be
+// completed by SeekResult.
+func (d *Device) SeekInProgress() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pending.kind == pendingSeek
+}
+
+// SeekResult reads the status and reports whether the seek started by
+// StartSeek is done, along with the error Seek would have returned. It
+// reports done when no seek is in progress.
+func (d *Device) SeekResult() (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending.kind != pendingSeek {
+		return true, nil
+	}
+	if err := d.readRegisters(); err != nil {
+		return false, d.registerError("seek", err)
+	}
+	var result error
+	select {
+	case <-d.abort:
+		result = ErrSeekAborted
+	default:
+	}
+	if result == nil && d.registers[STATUSRSSI]&(1<<STC) == 0 {
+		timeout := d.timings.SeekTimeout
+		if timeout == 0 || time.Since(d.pending.since) < timeout {
+			d.seekProgress()
+			return false, nil
+		}
+		result = ErrSeekTimeout
+	}
+	err := d.clearSeek(result)
+	d.postTune()
+	// an AFC railed stop is not a valid station, carry on seeking
+	if err == nil && d.afcRailed() && d.pending.retries < maxAFCRetries {
+		d.log(SubsystemTune, LevelDebug, "AFC railed, seeking on")
+		if err = d.startSeek(d.pending.dir); err == nil {
+			d.pending.retries++
+			d.pending.since = time.Now()
+			return false, nil
+		}
+	}
+	d.pending = pendingOp{}
+	return true, d.seekDone(err)
+}
+
+// StartTune starts tuning to the channel nearest to khz but returns
+// without waiting for the tune to settle. Poll TuneResult until it reports
+// the tune done. Other tuning methods must not be called meanwhile.
+func (d *Device) StartTune(khz uint32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending.kind != pendingNone {
+		return ErrBusy
+	}
+	if err := d.startTune(khz); err != nil {
+		return d.registerError("tune", err)
+	}
+	d.pending = pendingOp{kind: pendingTune, since: time.Now()}
+	return nil
+}
+
+// TuneInProgress reports whether a tune started by StartTune has yet to be
+// completed by TuneResult.
+func (d *Device) TuneInProgress() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pending.kind == pendingTune
+}
+
+// TuneResult reads the status and reports whether the tune started by
+// StartTune is done, along with the error SetChannel would have returned.
+// It reports done when no tune is in progress.
+func (d *Device) TuneResult() (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending.kind != pendingTune {
+		return true, nil
+	}
+	if err := d.readRegisters(); err != nil {
+		return false, d.registerError("tune", err)
+	}
+	var result error
+	if d.registers[STATUSRSSI]&(1<<STC) == 0 {
+		timeout := d.timings.TuneTimeout
+		if timeout == 0 || time.Since(d.pending.since) < timeout {
+			return false, nil
+		}
+		result = ErrTuneTimeout
+	}
+	err := d.clearTune(result)
+	d.postTune()
+	d.pending = pendingOp{}
+	return true, d.tuneDone(err)
+}
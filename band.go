@@ -0,0 +1,66 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "errors"
+
+// ErrInvalidBand is returned by SetBand for the reserved band value.
+var ErrInvalidBand = errors.New("si4703: invalid band")
+
+// Band is the frequency range tuned, set with the BAND bits of SYSCONFIG2.
+type Band uint8
+
+const (
+	BandUSEurope  Band = iota // 87.5-108 MHz, the power-up default
+	BandJapanWide             // 76-108 MHz
+	BandJapan                 // 76-90 MHz
+)
+
+// Bottom returns the lowest frequency of the band in kHz, channel 0.
+func (b Band) Bottom() uint32 {
+	if b == BandUSEurope {
+		return 87500
+	}
+	return 76000
+}
+
+// Top returns the highest frequency of the band in kHz.
+func (b Band) Top() uint32 {
+	if b == BandJapan {
+		return 90000
+	}
+	return 108000
+}
+
+// SetBand sets the band. The tuned channel number is kept, so retune
+// afterwards.
+func (d *Device) SetBand(b Band) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if b > BandJapan {
+		return ErrInvalidBand
+	}
+	return d.modify("band", func() {
+		d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2]&^(0x3<<BAND0) | uint16(b&0x3)<<BAND0
+	})
+}
+
+// Band returns the band. It is taken from the configuration last written,
+// so the bus is not accessed.
+func (d *Device) Band() Band {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.band()
+}
+
+// band returns the band from the registers last read.
+func (d *Device) band() Band {
+	return Band(d.registers[SYSCONFIG2] >> BAND0 & 0x3)
+}
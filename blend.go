@@ -0,0 +1,43 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// BlendThreshold is the BLNDADJ setting of SYSCONFIG1, named after the
+// shift of the RSSI range over which the audio blends from stereo to mono.
+type BlendThreshold uint8
+
+const (
+	BlendDefault   BlendThreshold = iota // 31–49 dBµV, the power-up default
+	BlendPlus6dB                         // 37–55 dBµV
+	BlendMinus12dB                       // 19–37 dBµV
+	BlendMinus6dB                        // 25–43 dBµV
+)
+
+// SetBlendAdjustment sets the BLNDADJ field of SYSCONFIG1, which selects
+// the RSSI range over which the audio blends from stereo to mono. Lower
+// ranges keep weak stations in stereo, higher ones switch to mono earlier
+// and so hiss less.
+func (d *Device) SetBlendAdjustment(adj BlendThreshold) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("blend adjustment", func() {
+		d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1]&^(0x3<<BLNDADJ) | uint16(adj&0x3)<<BLNDADJ
+	})
+}
+
+// BlendAdjustment returns the BLNDADJ field of SYSCONFIG1.
+func (d *Device) BlendAdjustment() (BlendThreshold, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return 0, d.registerError("read blend adjustment", err)
+	}
+	return BlendThreshold(d.registers[SYSCONFIG1] >> BLNDADJ & 0x3), nil
+}
@@ -0,0 +1,197 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package blend steadies the audio of fringe stations by adjusting the
+// chip's stereo/mono blend range. When the stereo indicator keeps flapping
+// while the RSSI sits near the blend threshold, the controller raises the
+// range so the station settles in mono; once reception has been steady for
+// a while it steps back towards the preferred setting.
+//
+//	c := blend.New(&fm)
+//	c.OnEvent = func(e blend.Event) { println(e.Reason) }
+//	c.Run()
+package blend
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// BLNDADJ settings ordered from the lowest to the highest RSSI blend range,
+// that is from the most to the least stereo.
+var ranges = [...]struct {
+	adj si4703.BlendThreshold
+	top uint8 // dBµV above which the audio is full stereo
+}{
+	{si4703.BlendMinus12dB, 37},
+	{si4703.BlendMinus6dB, 43},
+	{si4703.BlendDefault, 49}, // the power-up default
+	{si4703.BlendPlus6dB, 55},
+}
+
+const (
+	DefaultWindow    = 10 * time.Second
+	DefaultMaxFlaps  = 4
+	DefaultStableFor = time.Minute
+	DefaultInterval  = 200 * time.Millisecond
+)
+
+const (
+	ReasonFlapping = "stereo flapping"
+	ReasonStable   = "reception stable"
+)
+
+// Event describes a change of the blend adjustment.
+type Event struct {
+	Time   time.Time
+	From   si4703.BlendThreshold // previous BLNDADJ setting
+	To     si4703.BlendThreshold // new BLNDADJ setting
+	Reason string
+	Flaps  int   // stereo/mono changes seen in the last window
+	RSSI   uint8 // dBµV when the change was made
+}
+
+// Controller adjusts the blend range of one device.
+type Controller struct {
+	// Preferred is the BLNDADJ setting used on good reception.
+	Preferred si4703.BlendThreshold
+	// Window is the period over which stereo flaps are counted.
+	Window time.Duration
+	// MaxFlaps is the number of stereo/mono changes per Window above
+	// which the blend range is raised.
+	MaxFlaps int
+	// StableFor is how long reception must be free of excess flapping
+	// before the blend range is lowered again.
+	StableFor time.Duration
+	// Interval is how often Run samples the stereo indicator.
+	Interval time.Duration
+
+	OnEvent func(Event)
+
+	dev       *si4703.Device
+	level     int
+	frequency uint32
+	stereo    bool
+	flaps     int
+	window    time.Time
+	stable    time.Time
+}
+
+func New(dev *si4703.Device) *Controller {
+	return &Controller{
+		Window:    DefaultWindow,
+		MaxFlaps:  DefaultMaxFlaps,
+		StableFor: DefaultStableFor,
+		Interval:  DefaultInterval,
+		dev:       dev,
+		level:     -1,
+	}
+}
+
+// Run samples the stereo indicator every Interval until the device fails.
+func (c *Controller) Run() error {
+	return c.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (c *Controller) RunContext(ctx context.Context) error {
+	for {
+		if err := c.Check(time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Interval):
+		}
+	}
+}
+
+// Check samples the stereo indicator at time now and adjusts the blend
+// range when a window has passed. Retuning goes back to Preferred.
+func (c *Controller) Check(now time.Time) error {
+	status, err := c.dev.Status()
+	if err != nil {
+		return err
+	}
+	if c.level < 0 || status.Frequency != c.frequency {
+		c.frequency = status.Frequency
+		c.stereo = status.Stereo
+		c.flaps = 0
+		c.window = now
+		c.stable = now
+		return c.set(now, c.preferred(), "", status.RSSI)
+	}
+	if status.Stereo != c.stereo {
+		c.stereo = status.Stereo
+		c.flaps++
+	}
+	if now.Sub(c.window) < c.Window {
+		return nil
+	}
+
+	switch {
+	case c.flaps > c.MaxFlaps && status.RSSI < ranges[c.level].top:
+		// flapping inside the blend range, raising it moves the station
+		// into mono for good
+		c.stable = now
+		if c.level < len(ranges)-1 {
+			err = c.set(now, c.level+1, ReasonFlapping, status.RSSI)
+		}
+	case now.Sub(c.stable) >= c.StableFor:
+		c.stable = now
+		if c.level > c.preferred() {
+			err = c.set(now, c.level-1, ReasonStable, status.RSSI)
+		}
+	}
+	c.flaps = 0
+	c.window = now
+	return err
+}
+
+// preferred returns the position of Preferred in ranges.
+func (c *Controller) preferred() int {
+	for i, r := range ranges {
+		if r.adj == c.Preferred {
+			return i
+		}
+	}
+	return 2
+}
+
+// set changes the blend range, reporting it unless reason is empty.
+func (c *Controller) set(now time.Time, level int, reason string, rssi uint8) error {
+	from, err := c.dev.BlendAdjustment()
+	if err != nil {
+		return err
+	}
+	to := ranges[level].adj
+	if from != to {
+		if err := c.dev.SetBlendAdjustment(to); err != nil {
+			return err
+		}
+	}
+	c.level = level
+	if from == to {
+		return nil
+	}
+	if reason != "" && c.OnEvent != nil {
+		c.OnEvent(Event{
+			Time:   now,
+			From:   from,
+			To:     to,
+			Reason: reason,
+			Flaps:  c.flaps,
+			RSSI:   rssi,
+		})
+	}
+	return nil
+}
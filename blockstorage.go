@@ -0,0 +1,159 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+var ErrStorageFull = errors.New("si4703: storage full")
+
+// BlockDevice is flash memory or the like that is erased in blocks before
+// it is written. On tinygo targets with on-chip flash, machine.Flash
+// satisfies it.
+type BlockDevice interface {
+	io.ReaderAt
+	io.WriterAt
+	Size() int64
+	WriteBlockSize() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+// block storage record: magic, payload length, payload, CRC-32 of payload
+var blockMagic = [4]byte{'S', 'I', '4', '7'}
+
+const blockHeader = 6
+
+// BlockStorage is a Storage that keeps all keys in one record at the start
+// of a BlockDevice, rewriting the record whenever a key changes. It suits
+// the few small, rarely written blobs of a radio such as its presets and
+// last station:
+//
+//	storage := si4703.NewBlockStorage(machine.Flash)
+//	presets.Load(storage)
+type BlockStorage struct {
+	dev     BlockDevice
+	entries map[string][]byte
+}
+
+func NewBlockStorage(dev BlockDevice) *BlockStorage {
+	return &BlockStorage{dev: dev}
+}
+
+func (s *BlockStorage) Load(key string) ([]byte, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	data, ok := s.entries[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Store writes the record back only if data differs from what is stored,
+// to spare the flash.
+func (s *BlockStorage) Store(key string, data []byte) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	if old, ok := s.entries[key]; ok && bytes.Equal(old, data) {
+		return nil
+	}
+	if len(key) > 255 || len(data) > 0xFFFF {
+		return ErrStorageFull
+	}
+	s.entries[key] = append([]byte(nil), data...)
+	return s.write()
+}
+
+// load reads the record once. A blank or corrupt device reads as empty.
+func (s *BlockStorage) load() error {
+	if s.entries != nil {
+		return nil
+	}
+	entries := make(map[string][]byte)
+	var header [blockHeader]byte
+	if _, err := s.dev.ReadAt(header[:], 0); err != nil {
+		return err
+	}
+	if !bytes.Equal(header[:4], blockMagic[:]) {
+		s.entries = entries
+		return nil
+	}
+	n := int64(binary.BigEndian.Uint16(header[4:]))
+	if blockHeader+n+4 > s.dev.Size() {
+		s.entries = entries
+		return nil
+	}
+	buf := make([]byte, n+4)
+	if _, err := s.dev.ReadAt(buf, blockHeader); err != nil {
+		return err
+	}
+	payload := buf[:n]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(buf[n:]) {
+		s.entries = entries
+		return nil
+	}
+	for len(payload) > 0 {
+		kl := int(payload[0])
+		if len(payload) < 1+kl+2 {
+			break
+		}
+		key := string(payload[1 : 1+kl])
+		dl := int(binary.BigEndian.Uint16(payload[1+kl:]))
+		payload = payload[1+kl+2:]
+		if len(payload) < dl {
+			break
+		}
+		entries[key] = payload[:dl]
+		payload = payload[dl:]
+	}
+	s.entries = entries
+	return nil
+}
+
+// write erases as many blocks as the record needs and writes it.
+func (s *BlockStorage) write() error {
+	var payload []byte
+	for key, data := range s.entries {
+		payload = append(payload, byte(len(key)))
+		payload = append(payload, key...)
+		payload = binary.BigEndian.AppendUint16(payload, uint16(len(data)))
+		payload = append(payload, data...)
+	}
+	if len(payload) > 0xFFFF {
+		return ErrStorageFull
+	}
+	record := make([]byte, blockHeader, blockHeader+len(payload)+4)
+	copy(record, blockMagic[:])
+	binary.BigEndian.PutUint16(record[4:], uint16(len(payload)))
+	record = append(record, payload...)
+	record = binary.BigEndian.AppendUint32(record, crc32.ChecksumIEEE(payload))
+	if wb := s.dev.WriteBlockSize(); wb > 1 {
+		for int64(len(record))%wb != 0 {
+			record = append(record, 0xFF)
+		}
+	}
+	if int64(len(record)) > s.dev.Size() {
+		return ErrStorageFull
+	}
+	eb := s.dev.EraseBlockSize()
+	if err := s.dev.EraseBlocks(0, (int64(len(record))+eb-1)/eb); err != nil {
+		return err
+	}
+	_, err := s.dev.WriteAt(record, 0)
+	return err
+}
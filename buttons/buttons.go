@@ -0,0 +1,214 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+//go:build tinygo
+
+// Package buttons maps debounced GPIO push buttons to tuner actions.
+//
+//	c := buttons.New()
+//	c.Bind(machine.GP2, buttons.SeekUp(&fm), nil)
+//	c.Bind(machine.GP3, buttons.SeekDown(&fm), nil)
+//	c.Bind(machine.GP4, buttons.ToggleMute(&fm), nil)
+//	p := buttons.NewPresets(&fm, 4)
+//	c.Bind(machine.GP5, p.Recall(0), p.Store(0))
+//	c.Run(5 * time.Millisecond)
+package buttons
+
+import (
+	"context"
+	"machine"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+const (
+	DefaultDebounce  = 30 * time.Millisecond
+	DefaultLongPress = 800 * time.Millisecond
+)
+
+// Controller polls a set of buttons and runs their actions.
+type Controller struct {
+	Debounce  time.Duration
+	LongPress time.Duration
+	// OnError, if set, is called with the error of each failed action.
+	OnError func(error)
+
+	buttons []*button
+}
+
+type button struct {
+	pin       machine.Pin
+	press     func() error
+	longPress func() error
+
+	raw       bool
+	rawSince  time.Time
+	pressed   bool
+	pressedAt time.Time
+	longFired bool
+}
+
+func New() *Controller {
+	return &Controller{
+		Debounce:  DefaultDebounce,
+		LongPress: DefaultLongPress,
+	}
+}
+
+// Bind configures pin as an input with pull-up, with the button expected to
+// short it to ground. press runs when the button is released before the
+// long-press time, longPress runs once the button has been held that long.
+// If longPress is nil, press runs as soon as the button goes down.
+func (c *Controller) Bind(pin machine.Pin, press, longPress func() error) {
+	pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	c.buttons = append(c.buttons, &button{
+		pin:       pin,
+		press:     press,
+		longPress: longPress,
+	})
+}
+
+// Poll samples every button once and runs any actions that became due.
+func (c *Controller) Poll() {
+	now := time.Now()
+	for _, b := range c.buttons {
+		c.poll(b, now)
+	}
+}
+
+// Run calls Poll every interval, forever.
+func (c *Controller) Run(interval time.Duration) {
+	c.RunContext(context.Background(), interval)
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (c *Controller) RunContext(ctx context.Context, interval time.Duration) error {
+	for {
+		c.Poll()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (c *Controller) poll(b *button, now time.Time) {
+	raw := !b.pin.Get()
+	if raw != b.raw {
+		b.raw = raw
+		b.rawSince = now
+		return
+	}
+	if raw != b.pressed && now.Sub(b.rawSince) >= c.Debounce {
+		b.pressed = raw
+		if b.pressed {
+			b.pressedAt = now
+			b.longFired = false
+			if b.longPress == nil {
+				c.run(b.press)
+			}
+		} else if b.longPress != nil && !b.longFired {
+			c.run(b.press)
+		}
+		return
+	}
+	if b.pressed && b.longPress != nil && !b.longFired && now.Sub(b.pressedAt) >= c.LongPress {
+		b.longFired = true
+		c.run(b.longPress)
+	}
+}
+
+// run runs an action, passing its error to OnError.
+func (c *Controller) run(action func() error) {
+	if action == nil {
+		return
+	}
+	if err := action(); err != nil && c.OnError != nil {
+		c.OnError(err)
+	}
+}
+
+// SeekUp returns an action that seeks to the next station up the band.
+func SeekUp(dev *si4703.Device) func() error {
+	return func() error {
+		_, err := dev.Seek(1)
+		return err
+	}
+}
+
+// SeekDown returns an action that seeks to the next station down the band.
+func SeekDown(dev *si4703.Device) func() error {
+	return func() error {
+		_, err := dev.Seek(0)
+		return err
+	}
+}
+
+// VolumeUp returns an action that raises the volume by one 2 dB step of
+// the extended scale, see si4703.Device.SetVolumeExtended.
+func VolumeUp(dev *si4703.Device) func() error {
+	return func() error {
+		v, err := dev.VolumeExtended()
+		if err != nil || v >= 30 {
+			return err
+		}
+		return dev.SetVolumeExtended(v + 1)
+	}
+}
+
+// VolumeDown returns an action that lowers the volume by one 2 dB step of
+// the extended scale.
+func VolumeDown(dev *si4703.Device) func() error {
+	return func() error {
+		v, err := dev.VolumeExtended()
+		if err != nil || v == 0 {
+			return err
+		}
+		return dev.SetVolumeExtended(v - 1)
+	}
+}
+
+// ToggleMute returns an action that mutes or unmutes the audio.
+func ToggleMute(dev *si4703.Device) func() error {
+	return func() error {
+		muted, err := dev.Muted()
+		if err != nil {
+			return err
+		}
+		if muted {
+			return dev.DisableMute()
+		}
+		return dev.EnableMute()
+	}
+}
+
+// Presets holds a fixed number of station memories for button recall.
+type Presets struct {
+	presets *si4703.Presets
+}
+
+func NewPresets(dev *si4703.Device, n int) *Presets {
+	return &Presets{si4703.NewPresets(dev, n)}
+}
+
+// Recall returns an action that tunes the frequency stored in slot, if any.
+func (p *Presets) Recall(slot int) func() error {
+	return func() error {
+		return p.presets.Recall(slot)
+	}
+}
+
+// Store returns an action that saves the current frequency into slot.
+func (p *Presets) Store(slot int) func() error {
+	return func() error {
+		return p.presets.StoreCurrent(slot)
+	}
+}
@@ -0,0 +1,42 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// RSSICalibration corrects the RSSI the chip reports so that several
+// receivers used for comparative field strength logging agree.
+type RSSICalibration struct {
+	// Offset in dB is added to the chip's reading, found by comparing
+	// the receiver against a reference on the same signal.
+	Offset int8
+	// AntennaFactor in dB/m converts the level at the antenna input to
+	// field strength, making reported values dBµV/m instead of dBµV.
+	AntennaFactor int8
+}
+
+// SetRSSICalibration applies c to every RSSI the device reports, in Status,
+// CurrentStation, HealthCheck and the RSSI metric. Seek thresholds are not
+// affected and stay in the chip's own units.
+func (d *Device) SetRSSICalibration(c RSSICalibration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rssiCal = c
+}
+
+// rssi returns the calibrated RSSI from the STATUSRSSI register value.
+func (d *Device) rssi(status uint16) uint8 {
+	v := int(status&0xFF) + int(d.rssiCal.Offset) + int(d.rssiCal.AntennaFactor)
+	if v < 0 {
+		return 0
+	}
+	if v > 0xFF {
+		return 0xFF
+	}
+	return uint8(v)
+}
@@ -0,0 +1,89 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// PI codes of RBDS call letters, see NRSC-4 annex D.
+const (
+	piCallK      = 0x1000 // K + 3 letters from here
+	piCallW      = 0x54A8 // W + 3 letters from here
+	piCall3      = 0x9950 // 3 letter call signs from here
+	piCall3Limit = 0x9F00
+)
+
+// threeLetterCalls maps the PI codes of the few stations with three
+// letter call signs to them.
+var threeLetterCalls = map[uint16]string{
+	0x99A5: "KBW", 0x99A6: "KCY", 0x9990: "KDB", 0x99A7: "KDF",
+	0x9950: "KEX", 0x9951: "KFH", 0x9952: "KFI", 0x9953: "KGA",
+	0x9991: "KGB", 0x9954: "KGO", 0x9955: "KGU", 0x9956: "KGW",
+	0x9957: "KGY", 0x99AA: "KHQ", 0x9958: "KID", 0x9959: "KIT",
+	0x995A: "KJR", 0x995B: "KLO", 0x995C: "KLZ", 0x995D: "KMA",
+	0x995E: "KMJ", 0x995F: "KNX", 0x9960: "KOA", 0x99AB: "KOB",
+	0x9992: "KOY", 0x9993: "KPQ", 0x9964: "KQV", 0x9994: "KSD",
+	0x9965: "KSL", 0x9966: "KUJ", 0x9995: "KUT", 0x9967: "KVI",
+	0x9968: "KWG", 0x9996: "KXL", 0x9997: "KXO", 0x996B: "KYW",
+	0x9999: "WBT", 0x996D: "WBZ", 0x996E: "WDZ", 0x996F: "WEW",
+	0x999A: "WGH", 0x9971: "WGL", 0x9972: "WGN", 0x9973: "WGR",
+	0x999B: "WGY", 0x9975: "WHA", 0x9976: "WHB", 0x9977: "WHK",
+	0x9978: "WHO", 0x999C: "WHP", 0x999D: "WIL", 0x997A: "WIP",
+	0x99B3: "WIS", 0x997B: "WJR", 0x99B4: "WJW", 0x99B5: "WJZ",
+	0x997C: "WKY", 0x997D: "WLS", 0x997E: "WLW", 0x999E: "WMC",
+	0x999F: "WMT", 0x9981: "WOC", 0x99A0: "WOI", 0x9983: "WOL",
+	0x9984: "WOR", 0x99A1: "WOW", 0x99B9: "WRC", 0x99A2: "WRR",
+	0x99A3: "WSB", 0x99A4: "WSM", 0x9988: "WWJ", 0x9989: "WWL",
+}
+
+// Callsign returns the call letters an RBDS station with PI code pi was
+// assigned, such as "KQED", or "" for a code that does not encode call
+// letters, like the nationally and regionally linked codes of networks.
+func Callsign(pi uint16) string {
+	// codes that would have a zero nibble are sent as AFxy for xy00 and
+	// Axyz for x0yz
+	if pi&0xFF00 == 0xAF00 {
+		pi <<= 8
+	} else if pi>>12 == 0xA {
+		pi = pi&0x0F00<<4 | pi&0xFF
+	}
+	switch {
+	case pi >= piCall3 && pi < piCall3Limit:
+		return threeLetterCalls[pi]
+	case pi >= piCallW && pi < piCall3:
+		return callLetters('W', pi-piCallW)
+	case pi >= piCallK && pi < piCallW:
+		return callLetters('K', pi-piCallK)
+	}
+	return ""
+}
+
+// callLetters appends the three letters n encodes in base 26 to first.
+func callLetters(first byte, n uint16) string {
+	return string([]byte{
+		first,
+		'A' + byte(n/676),
+		'A' + byte(n/26%26),
+		'A' + byte(n%26),
+	})
+}
+
+// Callsign returns the call letters of the tuned station when the RBDS
+// standard is selected, see SetRDSStandard, or "" otherwise and before the
+// PI code is received.
+func (d *Device) Callsign() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.callsign()
+}
+
+func (d *Device) callsign() string {
+	if d.standard != StandardRBDS || d.decoder.pi == 0 {
+		return ""
+	}
+	return Callsign(d.decoder.pi)
+}
@@ -0,0 +1,64 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "testing"
+
+func TestCallsign(t *testing.T) {
+	tests := []struct {
+		pi   uint16
+		want string
+	}{
+		{0x0000, ""},
+		{0x0FFF, ""},
+		// K from 0x1000
+		{0x1000, "KAAA"},
+		{0x3AAB, "KQED"},
+		{0x54A7, "KZZZ"},
+		// W from 0x54A8
+		{0x54A8, "WAAA"},
+		{0x994F, "WZZZ"},
+		// three letter calls from 0x9950
+		{0x9950, "KEX"},
+		{0x99A5, "KBW"},
+		{0x996B, "KYW"},
+		{0x9972, "WGN"},
+		{0x9989, "WWL"},
+		{0x9961, ""},
+		{0x9EFF, ""},
+		// AFxy is sent for xy00, Axyz for x0yz
+		{0xAF12, "KATS"},
+		{0xAF54, "KZTO"},
+		{0xA123, "KABJ"},
+		{0xA6A8, "WEOE"},
+		{0xA000, ""},
+		// nationally and regionally linked network codes
+		{0x9F00, ""},
+		{0xB201, ""},
+		{0xFFFF, ""},
+	}
+	for _, test := range tests {
+		if got := Callsign(test.pi); got != test.want {
+			t.Errorf("Callsign(%04X) = %q, want %q", test.pi, got, test.want)
+		}
+	}
+}
+
+func TestDeviceCallsignNeedsRBDS(t *testing.T) {
+	d := New(nil)
+	d.decoder.pi = 0x3AAB
+	if got := d.Callsign(); got != "" {
+		t.Errorf("RDS callsign = %q, want none", got)
+	}
+	d.SetRDSStandard(StandardRBDS)
+	if got := d.Callsign(); got != "KQED" {
+		t.Errorf("RBDS callsign = %q, want KQED", got)
+	}
+}
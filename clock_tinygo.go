@@ -0,0 +1,28 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+//go:build tinygo
+
+package si4703
+
+import (
+	"runtime"
+	"time"
+)
+
+// SyncSystemClock sets the system clock, as returned by time.Now, from the
+// RDS clock time of the tuned station, see ClockSync for the checks made.
+// It replaces any handler installed with OnClockTime.
+func (d *Device) SyncSystemClock() *ClockSync {
+	c := NewClockSync(func(t time.Time) {
+		runtime.AdjustTimeOffset(int64(t.Sub(time.Now())))
+	})
+	d.OnClockTime(c.Handle)
+	return c
+}
@@ -0,0 +1,59 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "time"
+
+// maxClockSkew is how far the interval between two clock time groups may
+// differ from the interval measured locally.
+const maxClockSkew = 2 * time.Second
+
+// maxClockGap is the longest interval between clock time groups that are
+// compared, ten groups missed in a row.
+const maxClockGap = 10 * time.Minute
+
+// ClockSync sets a clock from RDS clock time, for clock radios without
+// another time source. A single group may be corrupt or come from a
+// station with a wrong clock, so the clock is only set once two groups in
+// a row agree with each other and with the time that passed locally
+// between them. Install its Handle method with OnClockTime.
+type ClockSync struct {
+	set func(time.Time)
+	// last clock time received and the local time it arrived
+	last   time.Time
+	lastAt time.Time
+	// Synced is when the clock was last set, zero until then.
+	Synced time.Time
+}
+
+// NewClockSync returns a ClockSync that calls set with the verified time.
+func NewClockSync(set func(time.Time)) *ClockSync {
+	return &ClockSync{set: set}
+}
+
+// Handle checks a received clock time against the one before and sets the
+// clock if they agree.
+func (c *ClockSync) Handle(ct time.Time) {
+	now := time.Now()
+	last, lastAt := c.last, c.lastAt
+	c.last, c.lastAt = ct, now
+	if last.IsZero() {
+		return
+	}
+	gap := ct.Sub(last)
+	if gap <= 0 || gap > maxClockGap {
+		return
+	}
+	if skew := gap - now.Sub(lastAt); skew > maxClockSkew || skew < -maxClockSkew {
+		return
+	}
+	c.set(ct)
+	c.Synced = now
+}
@@ -0,0 +1,42 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "time"
+
+// mjdUnixEpoch is the modified Julian day of 1970-01-01.
+const mjdUnixEpoch = 40587
+
+// decodeClockTime decodes the clock time and date of a group 4A, in the
+// broadcaster's local time zone. It reports false for out of range values.
+func decodeClockTime(g RDSGroup) (time.Time, bool) {
+	mjd := int64(g.B&0x3)<<15 | int64(g.C>>1)
+	hour := int(g.C&0x1)<<4 | int(g.D>>12)
+	minute := int(g.D >> 6 & 0x3F)
+	offset := int(g.D&0x1F) * 30 * 60 // half hours
+	if g.D>>5&0x1 == 1 {
+		offset = -offset
+	}
+	if mjd <= mjdUnixEpoch || hour > 23 || minute > 59 || offset > 14*3600 || offset < -12*3600 {
+		return time.Time{}, false
+	}
+	utc := time.Unix((mjd-mjdUnixEpoch)*86400+int64(hour*3600+minute*60), 0)
+	return utc.In(time.FixedZone("", offset)), true
+}
+
+// OnClockTime makes the device call f with the time each RDS clock time
+// group carries, in the broadcaster's local time zone. Stations send one
+// at the start of every minute. f is called with the device locked, so it
+// must not call back into the device. Pass nil to stop.
+func (d *Device) OnClockTime(f func(time.Time)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onClockTime = f
+}
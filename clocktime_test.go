@@ -0,0 +1,111 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"testing"
+	"time"
+)
+
+// clockTimeGroup builds a group 4A for a UTC date as MJD, time and local
+// offset in signed half hours.
+func clockTimeGroup(mjd uint32, hour, minute uint16, halfHours int) RDSGroup {
+	var sign uint16
+	if halfHours < 0 {
+		sign, halfHours = 1, -halfHours
+	}
+	return RDSGroup{
+		A: 0xC201,
+		B: 0x4000 | uint16(mjd>>15),
+		C: uint16(mjd&0x7FFF)<<1 | hour>>4,
+		D: hour&0xF<<12 | minute<<6 | sign<<5 | uint16(halfHours),
+	}
+}
+
+func TestDecodeClockTime(t *testing.T) {
+	// MJD 60431 is 2024-05-01
+	tests := []struct {
+		name      string
+		group     RDSGroup
+		want      time.Time // UTC
+		wantLocal string
+		wantZone  int // seconds east of UTC
+	}{
+		{
+			name:      "UTC",
+			group:     clockTimeGroup(60431, 18, 3, 0),
+			want:      time.Date(2024, 5, 1, 18, 3, 0, 0, time.UTC),
+			wantLocal: "2024-05-01 18:03",
+		},
+		{
+			name:      "positive half hour offset",
+			group:     clockTimeGroup(60431, 18, 3, 11),
+			want:      time.Date(2024, 5, 1, 18, 3, 0, 0, time.UTC),
+			wantLocal: "2024-05-01 23:33",
+			wantZone:  5*3600 + 1800,
+		},
+		{
+			name:      "negative half hour offset",
+			group:     clockTimeGroup(60431, 18, 3, -7),
+			want:      time.Date(2024, 5, 1, 18, 3, 0, 0, time.UTC),
+			wantLocal: "2024-05-01 14:33",
+			wantZone:  -(3*3600 + 1800),
+		},
+		{
+			name:      "offset crosses midnight",
+			group:     clockTimeGroup(60431, 23, 45, 2),
+			want:      time.Date(2024, 5, 1, 23, 45, 0, 0, time.UTC),
+			wantLocal: "2024-05-02 00:45",
+			wantZone:  3600,
+		},
+		{
+			name:      "MJD above 16 bits",
+			group:     clockTimeGroup(65536, 0, 0, 0),
+			want:      time.Date(2038, 4, 23, 0, 0, 0, 0, time.UTC),
+			wantLocal: "2038-04-23 00:00",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := decodeClockTime(test.group)
+			if !ok {
+				t.Fatal("rejected")
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("got %v, want %v", got.UTC(), test.want)
+			}
+			if local := got.Format("2006-01-02 15:04"); local != test.wantLocal {
+				t.Errorf("local time %s, want %s", local, test.wantLocal)
+			}
+			if _, zone := got.Zone(); zone != test.wantZone {
+				t.Errorf("zone offset %d, want %d", zone, test.wantZone)
+			}
+		})
+	}
+}
+
+func TestDecodeClockTimeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		group RDSGroup
+	}{
+		{"zero MJD", clockTimeGroup(0, 18, 3, 0)},
+		{"MJD of the Unix epoch", clockTimeGroup(mjdUnixEpoch, 18, 3, 0)},
+		{"hour 24", clockTimeGroup(60431, 24, 0, 0)},
+		{"minute 60", clockTimeGroup(60431, 18, 60, 0)},
+		{"offset over 14 hours", clockTimeGroup(60431, 18, 3, 29)},
+		{"offset under -12 hours", clockTimeGroup(60431, 18, 3, -25)},
+	}
+	for _, test := range tests {
+		if got, ok := decodeClockTime(test.group); ok {
+			t.Errorf("%s: accepted as %v", test.name, got)
+		}
+	}
+}
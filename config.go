@@ -0,0 +1,87 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// Config is the configuration the chip is powered up with. The zero value
+// is the chip's power-up default, with RDS enabled and the volume at its
+// lowest audible step.
+type Config struct {
+	// ResetPin drives the chip's RST line. Nil keeps the pin the device
+	// was created with, GPIO15 on tinygo targets.
+	ResetPin Pin
+	// NoReset leaves RST alone, for boards that reset the chip by other
+	// means. ResetPin is ignored.
+	NoReset bool
+	// SDIOPin, if set, is the GPIO for the I2C data line. It is held low
+	// while RST rises, which is how the chip selects 2-wire (I2C) mode
+	// instead of 3-wire mode. The pin is then driven high again and
+	// RestoreBus is called to hand the line back to the I2C peripheral,
+	// for example by calling machine.I2C0.Configure.
+	SDIOPin    Pin
+	RestoreBus func() error
+
+	Band       Band
+	Spacing    Spacing
+	Deemphasis Deemphasis
+
+	// Volume is the initial volume, 1 to 15. Zero uses 1.
+	Volume uint16
+	// ResumeFrom, if set, is the storage SaveLastStation saved to. The
+	// saved station, volume and settings are restored after power up,
+	// taking the place of the ones above. Without a saved state the
+	// chip comes up as configured.
+	ResumeFrom Storage
+	// DisableRDS leaves the RDS receiver off.
+	DisableRDS bool
+	// StereoIndicator makes GPIO3 the stereo indicator from power up,
+	// see SetStereoIndicatorPin.
+	StereoIndicator bool
+	// WarnRegionMismatch logs a warning when the extended country code
+	// of a station shows that the de-emphasis or band does not match the
+	// country it broadcasts from, see StationInfo.RegionMismatch.
+	WarnRegionMismatch bool
+
+	// Seek thresholds, see SYSCONFIG2 and SYSCONFIG3 in the datasheet:
+	// the minimum RSSI (SEEKTH), the minimum SNR from 1 (lenient) to 15
+	// (strict) and the number of FM impulses allowed from 1 (strict) to
+	// 15 (lenient). Zero disables the SNR and impulse checks.
+	SeekThreshold    uint8
+	SeekSNR          uint8
+	SeekImpulseCount uint8
+}
+
+// applyConfig sets up the shadow registers for the enable write of the
+// power-up sequence.
+func (d *Device) applyConfig() {
+	c := d.config
+	r := d.registers[SYSCONFIG1] &^ (1<<RDS | 1<<DE)
+	if !c.DisableRDS {
+		r |= 1 << RDS
+	}
+	r &^= 0x3 << GPIO3
+	if c.StereoIndicator {
+		r |= gpio3StereoIndicator << GPIO3
+	}
+	d.registers[SYSCONFIG1] = r | uint16(c.Deemphasis&0x1)<<DE
+
+	volume := c.Volume
+	if volume == 0 {
+		volume = 1
+	} else if volume > 15 {
+		volume = 15
+	}
+	d.registers[SYSCONFIG2] = uint16(c.SeekThreshold)<<SEEKTH |
+		uint16(c.Band&0x3)<<BAND0 |
+		uint16(c.Spacing&0x3)<<SPACE0 |
+		volume
+	d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3]&0xFF00 |
+		uint16(c.SeekSNR&0xF)<<SKSNR |
+		uint16(c.SeekImpulseCount&0xF)<<SKCNT
+}
@@ -0,0 +1,256 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package console implements a small line oriented shell for driving an
+// Si4703 over a UART or USB serial port during bring-up and field debugging.
+//
+//	fm := si4703.New(machine.I2C0)
+//	fm.Configure(si4703.Config{})
+//	console.New(&fm, machine.Serial).Run()
+package console
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+const prompt = "fm> "
+
+var errUsage = errors.New("bad arguments, try help")
+
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *Shell, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"help", "help", "list commands", (*Shell).help},
+		{"tune", "tune <MHz>", "tune to a frequency, e.g. tune 90.9", (*Shell).tune},
+		{"seek", "seek up|down", "seek to the next station", (*Shell).seek},
+		{"vol", "vol <0-15>", "set the volume", (*Shell).vol},
+		{"scan", "scan", "seek through the whole band and list stations", (*Shell).scan},
+		{"rds", "rds [seconds]", "print received RDS groups (default 5s)", (*Shell).rds},
+		{"dump", "dump", "print the decoded registers", (*Shell).dump},
+	}
+}
+
+// Shell reads commands from a serial port and runs them against a Device.
+type Shell struct {
+	dev *si4703.Device
+	rw  io.ReadWriter
+	buf []byte
+}
+
+// New returns a Shell that talks to the user over rw. On tinygo targets rw
+// is usually machine.Serial.
+func New(dev *si4703.Device, rw io.ReadWriter) *Shell {
+	return &Shell{
+		dev: dev,
+		rw:  rw,
+		buf: make([]byte, 0, 64),
+	}
+}
+
+// Run prompts for and executes commands until reading from the port fails.
+func (s *Shell) Run() error {
+	s.print(prompt)
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+		if err = s.Exec(line); err != nil {
+			s.println("error: " + err.Error())
+		}
+		s.print(prompt)
+	}
+}
+
+// Exec runs a single command line.
+func (s *Shell) Exec(line string) error {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return nil
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(s, args[1:])
+		}
+	}
+	return errors.New("unknown command " + args[0] + ", try help")
+}
+
+// readLine collects one line of input, echoing characters back so that a
+// plain serial terminal behaves sensibly. Serial ports on tinygo return no
+// data rather than blocking, so empty reads are retried after a short nap.
+func (s *Shell) readLine() (string, error) {
+	s.buf = s.buf[:0]
+	c := make([]byte, 1)
+	for {
+		n, err := s.rw.Read(c)
+		if err != nil {
+			return "", err
+		}
+		if n == 0 {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		switch c[0] {
+		case '\r', '\n':
+			s.print("\r\n")
+			return string(s.buf), nil
+		case 0x08, 0x7F:
+			if len(s.buf) > 0 {
+				s.buf = s.buf[:len(s.buf)-1]
+				s.print("\b \b")
+			}
+		default:
+			s.buf = append(s.buf, c[0])
+			s.rw.Write(c)
+		}
+	}
+}
+
+func (s *Shell) print(str string) {
+	io.WriteString(s.rw, str)
+}
+
+func (s *Shell) println(str string) {
+	io.WriteString(s.rw, str+"\r\n")
+}
+
+func (s *Shell) help(args []string) error {
+	for _, c := range commands {
+		s.println(c.usage + strings.Repeat(" ", 16-len(c.usage)) + c.help)
+	}
+	return nil
+}
+
+func (s *Shell) tune(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	mhz, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return errUsage
+	}
+	if err := s.dev.SetFrequency(uint32(mhz*1000 + 0.5)); err != nil {
+		return err
+	}
+	return s.status()
+}
+
+func (s *Shell) seek(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	var err error
+	switch args[0] {
+	case "up":
+		_, err = s.dev.Seek(1)
+	case "down":
+		_, err = s.dev.Seek(0)
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+	return s.status()
+}
+
+func (s *Shell) vol(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	volume, err := strconv.ParseUint(args[0], 10, 16)
+	if err != nil || volume > 15 {
+		return errUsage
+	}
+	return s.dev.SetVolume(uint16(volume))
+}
+
+func (s *Shell) scan(args []string) error {
+	seen := make(map[uint32]bool)
+	for {
+		if _, err := s.dev.Seek(1); err == si4703.ErrSeekFailed {
+			break
+		} else if err != nil {
+			return err
+		}
+		status, err := s.dev.Status()
+		if err != nil {
+			return err
+		}
+		if seen[status.Frequency] {
+			break
+		}
+		seen[status.Frequency] = true
+		s.printStatus(status)
+	}
+	s.println(strconv.Itoa(len(seen)) + " stations")
+	return nil
+}
+
+func (s *Shell) rds(args []string) error {
+	seconds := 5
+	if len(args) == 1 {
+		var err error
+		if seconds, err = strconv.Atoi(args[0]); err != nil {
+			return errUsage
+		}
+	}
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+	for time.Now().Before(deadline) {
+		g, ok, err := s.dev.ReadRDS()
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.println("PI " + si4703.FormatPI(g.A) +
+				" group " + strconv.Itoa(int(g.B>>12)) + string(rune('A'+g.B>>11&0x1)) +
+				"  " + g.String())
+		}
+		time.Sleep(s.dev.Timings().RDSPoll)
+	}
+	return nil
+}
+
+func (s *Shell) dump(args []string) error {
+	s.print(strings.ReplaceAll(s.dev.String(), "\n", "\r\n"))
+	return nil
+}
+
+// status reads and prints the tuning status.
+func (s *Shell) status() error {
+	status, err := s.dev.Status()
+	if err != nil {
+		return err
+	}
+	s.printStatus(status)
+	return nil
+}
+
+func (s *Shell) printStatus(status si4703.Status) {
+	line := s.dev.Spacing().FormatMHz(status.Frequency) + " MHz  RSSI " + strconv.Itoa(int(status.RSSI))
+	if status.Stereo {
+		line += "  stereo"
+	}
+	s.println(line)
+}
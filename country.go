@@ -0,0 +1,104 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// countries maps an extended country code and the country nibble of the PI
+// code, 1 to F, to an ISO 3166 country code. Empty entries are unassigned
+// or not listed.
+var countries = map[uint8][15]string{
+	// Europe
+	0xE0: {"DE", "DZ", "AD", "IL", "IT", "BE", "RU", "PS", "AL", "AT", "HU", "MT", "DE", "", "EG"},
+	0xE1: {"GR", "CY", "SM", "CH", "JO", "FI", "LU", "BG", "DK", "GI", "IQ", "GB", "LY", "RO", "FR"},
+	0xE2: {"MA", "CZ", "PL", "VA", "SK", "SY", "TN", "", "LI", "IS", "MC", "LT", "RS", "ES", "NO"},
+	0xE3: {"ME", "IE", "TR", "MK", "", "", "", "NL", "LV", "LB", "AZ", "HR", "KZ", "SE", "BY"},
+	0xE4: {"MD", "EE", "KG", "", "", "UA", "", "PT", "SI", "AM", "", "GE", "", "", "BA"},
+	// North America
+	0xA0: {"US", "US", "US", "US", "US", "US", "US", "US", "US", "US", "US", "", "US", "US", ""},
+	0xA1: {"", "", "", "", "", "", "", "", "", "", "CA", "CA", "CA", "CA", ""},
+	0xA5: {"", "", "", "", "", "", "", "", "", "", "MX", "", "MX", "MX", "MX"},
+	// Asia and the Pacific
+	0xF0: {"AU", "AU", "AU", "AU", "AU", "AU", "AU", "AU", "SA", "AF", "MM", "CN", "KP", "BH", "MY"},
+	0xF1: {"KI", "BT", "BD", "PK", "FJ", "OM", "NR", "IR", "NZ", "SB", "BN", "LK", "TW", "KR", "HK"},
+	0xF2: {"KW", "QA", "KH", "WS", "IN", "MO", "VN", "PH", "JP", "SG", "MV", "ID", "AE", "NP", "VU"},
+}
+
+// Country returns the ISO 3166 code of the country a station with the
+// given extended country code and PI code broadcasts from, or "" if it is
+// not known.
+func Country(ecc uint8, pi uint16) string {
+	nibble := pi >> 12
+	if nibble == 0 {
+		return ""
+	}
+	return countries[ecc][nibble-1]
+}
+
+// uses75us reports whether broadcasters in a country use 75 µs
+// de-emphasis rather than the 50 µs used nearly everywhere else.
+func uses75us(country string) bool {
+	switch country {
+	case "US", "CA", "MX", "KR":
+		return true
+	}
+	return false
+}
+
+// usesJapanBand reports whether broadcasters in a country use the band
+// starting at 76 MHz rather than the one starting at 87.5 MHz.
+func usesJapanBand(country string) bool {
+	return country == "JP"
+}
+
+// regionMismatch reports whether the de-emphasis or band the device is
+// configured for differs from the one used in country.
+func (d *Device) regionMismatch(country string) bool {
+	return d.deemphasisMismatch(country) || d.bandMismatch(country)
+}
+
+func (d *Device) deemphasisMismatch(country string) bool {
+	if country == "" {
+		return false
+	}
+	return (d.deemphasis() == Deemphasis75us) != uses75us(country)
+}
+
+// bandMismatch reports whether the band leaves out the stations of
+// country. BandJapanWide covers every country.
+func (d *Device) bandMismatch(country string) bool {
+	if country == "" {
+		return false
+	}
+	switch d.band() {
+	case BandUSEurope:
+		return usesJapanBand(country)
+	case BandJapan:
+		return !usesJapanBand(country)
+	}
+	return false
+}
+
+// checkRegion warns when the country of a newly identified station does not
+// match the configured de-emphasis or band, if Config.WarnRegionMismatch is
+// set.
+func (d *Device) checkRegion() {
+	if !d.config.WarnRegionMismatch {
+		return
+	}
+	country := Country(d.decoder.ecc, d.decoder.pi)
+	if d.deemphasisMismatch(country) {
+		d.log(SubsystemRDS, LevelWarn, "station broadcasts from "+country+
+			", de-emphasis is set to "+d.printDeemphasis(byte(d.deemphasis())))
+	}
+	if d.bandMismatch(country) {
+		b := d.band()
+		d.log(SubsystemRDS, LevelWarn, "station broadcasts from "+country+
+			", band is set to "+Spacing100kHz.FormatMHz(b.Bottom())+"-"+Spacing100kHz.FormatMHz(b.Top())+" MHz")
+	}
+}
@@ -0,0 +1,42 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// Deemphasis is the audio de-emphasis time constant, set with the DE bit of
+// SYSCONFIG1. It has to match the pre-emphasis used by broadcasters in the
+// region.
+type Deemphasis uint8
+
+const (
+	Deemphasis75us Deemphasis = iota // the Americas and South Korea, the power-up default
+	Deemphasis50us                   // Europe, Australia and Japan
+)
+
+// SetDeemphasis sets the de-emphasis time constant.
+func (d *Device) SetDeemphasis(de Deemphasis) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("deemphasis", func() {
+		d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1]&^(1<<DE) | uint16(de&0x1)<<DE
+	})
+}
+
+// Deemphasis returns the de-emphasis time constant. It is taken from the
+// configuration last written, so the bus is not accessed.
+func (d *Device) Deemphasis() Deemphasis {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.deemphasis()
+}
+
+// deemphasis returns the de-emphasis from the registers last read.
+func (d *Device) deemphasis() Deemphasis {
+	return Deemphasis(d.registers[SYSCONFIG1] >> DE & 0x1)
+}
@@ -0,0 +1,50 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// DeviceInfo identifies the chip, decoded from the DEVICEID and CHIPID
+// registers. The firmware version reads as 0 until the chip is powered up.
+type DeviceInfo struct {
+	PartNumber   uint8  // 1 for the Si4702/03
+	Manufacturer uint16 // 0x242 for Silicon Labs
+	Revision     uint8  // 4 for Rev C
+	Device       uint8  // 9 for an Si4703 powered up
+	Firmware     uint8
+
+	// Workarounds names the quirks of this revision and firmware that
+	// Configure enabled, for interpreting field reports.
+	Workarounds []string
+}
+
+// chip revision of the Si4702/03-C19
+const revC = 0x04
+
+// DeviceInfo reads the identification registers.
+func (d *Device) DeviceInfo() (DeviceInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return DeviceInfo{}, d.registerError("device info", err)
+	}
+	return d.deviceInfo(), nil
+}
+
+func (d *Device) deviceInfo() DeviceInfo {
+	id := d.registers[DEVICEID]
+	chip := d.registers[CHIPID]
+	return DeviceInfo{
+		PartNumber:   uint8(id >> 12),
+		Manufacturer: id & 0xFFF,
+		Revision:     uint8(chip >> 10),
+		Device:       uint8(chip >> 6 & 0xF),
+		Firmware:     uint8(chip & 0x3F),
+		Workarounds:  d.workarounds(),
+	}
+}
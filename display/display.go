@@ -0,0 +1,150 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package display renders a radio front panel for an Si4703 on any tinygo
+// display driver: frequency, signal bars, stereo and RDS indicators, the
+// station name and a scrolling RadioText line.
+//
+//	oled := ssd1306.NewI2C(machine.I2C0)
+//	oled.Configure(ssd1306.Config{Width: 128, Height: 64, Address: 0x3C})
+//	panel := display.New(&fm, &oled)
+//	for {
+//		fm.ReadRDS()
+//		panel.Draw()
+//		time.Sleep(200 * time.Millisecond)
+//	}
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinyfont"
+	"tinygo.org/x/tinyfont/freesans"
+
+	"github.com/mcilley/go-si4703"
+	"github.com/mcilley/go-si4703/marquee"
+)
+
+// Panel draws the tuner state onto a display.
+type Panel struct {
+	Foreground color.RGBA
+	Background color.RGBA
+	SmallFont  tinyfont.Fonter
+	LargeFont  tinyfont.Fonter
+
+	dev     *si4703.Device
+	disp    drivers.Displayer
+	marquee *marquee.Marquee
+}
+
+func New(dev *si4703.Device, disp drivers.Displayer) *Panel {
+	return &Panel{
+		Foreground: color.RGBA{255, 255, 255, 255},
+		Background: color.RGBA{0, 0, 0, 255},
+		SmallFont:  &tinyfont.TomThumb,
+		LargeFont:  &freesans.Bold9pt7b,
+		dev:        dev,
+		disp:       disp,
+		marquee:    &marquee.Marquee{Pause: marquee.DefaultPause, Gap: marquee.DefaultGap},
+	}
+}
+
+// Draw reads the current status and redraws the whole panel. Each call
+// advances the RadioText line by one character, so the scroll speed is set
+// by how often Draw is called. A new text is held still for a moment
+// first.
+func (p *Panel) Draw() error {
+	status, err := p.dev.Status()
+	if err != nil {
+		return err
+	}
+	data := p.dev.RDSData()
+	w, h := p.disp.Size()
+
+	p.clear(w, h)
+
+	// top row: indicators, station name and signal strength
+	if status.Stereo {
+		p.write(p.SmallFont, 0, 6, "ST")
+	}
+	if status.RDSSynchronized {
+		p.write(p.SmallFont, 12, 6, "RDS")
+	}
+	p.write(p.SmallFont, 28, 6, data.ProgramService)
+	p.drawBars(w-19, 6, status.RSSI)
+
+	// frequency, centred
+	freq := p.dev.Spacing().FormatMHz(status.Frequency)
+	_, fw := tinyfont.LineWidth(p.LargeFont, freq)
+	_, uw := tinyfont.LineWidth(p.SmallFont, " MHz")
+	x := (w - int16(fw+uw)) / 2
+	p.write(p.LargeFont, x, h/2+6, freq)
+	p.write(p.SmallFont, x+int16(fw), h/2+6, " MHz")
+
+	// bottom row: radiotext
+	p.write(p.SmallFont, 0, h-2, p.scrollText(data.RadioText, w))
+
+	return p.disp.Display()
+}
+
+// signal bar thresholds in dBµV
+var bars = [...]uint8{10, 20, 30, 40, 50}
+
+// drawBars draws a five step signal meter with its bottom left at x, y.
+func (p *Panel) drawBars(x, y int16, rssi uint8) {
+	for i, threshold := range bars {
+		if rssi < threshold {
+			break
+		}
+		height := int16(i+1) + int16(i)/2
+		p.fill(x+int16(i)*4, y-height+1, 3, height)
+	}
+}
+
+// scrollText returns the part of the radiotext visible this frame.
+func (p *Panel) scrollText(text string, width int16) string {
+	_, cw := tinyfont.LineWidth(p.SmallFont, "0")
+	if cw > 0 {
+		p.marquee.Width = int(uint32(width) / cw)
+	}
+	return p.marquee.Frame(text, time.Now())
+}
+
+func (p *Panel) write(font tinyfont.Fonter, x, y int16, str string) {
+	if str == "" {
+		return
+	}
+	tinyfont.WriteLine(p.disp, font, x, y, str, p.Foreground)
+}
+
+func (p *Panel) fill(x, y, w, h int16) {
+	for i := x; i < x+w; i++ {
+		for j := y; j < y+h; j++ {
+			p.disp.SetPixel(i, j, p.Foreground)
+		}
+	}
+}
+
+// clear blanks the display using the fastest method the driver offers.
+func (p *Panel) clear(w, h int16) {
+	switch disp := p.disp.(type) {
+	case interface{ ClearBuffer() }:
+		disp.ClearBuffer()
+	case interface{ FillScreen(color.RGBA) }:
+		disp.FillScreen(p.Background)
+	default:
+		for x := int16(0); x < w; x++ {
+			for y := int16(0); y < h; y++ {
+				p.disp.SetPixel(x, y, p.Background)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package dxlog keeps a log of every RDS station heard, for FM DX band
+// opening watch. Left running, the logger sweeps the band, listens briefly
+// to each station it stops on and records its PI code together with the
+// frequency, first and last time heard, best signal and station name.
+//
+//	log := dxlog.New(&fm, storage)
+//	log.OnNew = func(e dxlog.Entry) { println("new PI", e.PI) }
+//	log.Run()
+package dxlog
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// StorageKey is the key the log is stored under.
+const StorageKey = "dxlog"
+
+const (
+	DefaultDwell        = 3 * time.Second
+	DefaultSaveInterval = 5 * time.Minute
+)
+
+// Entry is one PI code heard on one frequency.
+type Entry struct {
+	PI         uint16    `json:"pi"`
+	Frequency  uint32    `json:"freq"` // kHz
+	FirstHeard time.Time `json:"first"`
+	LastHeard  time.Time `json:"last"`
+	BestRSSI   uint8     `json:"rssi"`
+	PS         string    `json:"ps,omitempty"`
+}
+
+type key struct {
+	pi   uint16
+	freq uint32
+}
+
+// Logger records PI codes to a Storage.
+type Logger struct {
+	// Dwell is how long to listen for RDS at each stop of the sweep.
+	Dwell time.Duration
+	// SaveInterval limits how often updates to known entries are written
+	// back; new entries are always saved immediately.
+	SaveInterval time.Duration
+	// OnNew is called the first time a PI code is heard on a frequency.
+	OnNew func(Entry)
+
+	dev     *si4703.Device
+	storage si4703.Storage
+	entries map[key]*Entry
+	dirty   bool
+	saved   time.Time
+}
+
+// New returns a logger that continues the log found in storage, if any.
+func New(dev *si4703.Device, storage si4703.Storage) *Logger {
+	l := &Logger{
+		Dwell:        DefaultDwell,
+		SaveInterval: DefaultSaveInterval,
+		dev:          dev,
+		storage:      storage,
+		entries:      make(map[key]*Entry),
+	}
+	if data, err := storage.Load(StorageKey); err == nil {
+		var entries []Entry
+		if json.Unmarshal(data, &entries) == nil {
+			for i := range entries {
+				e := &entries[i]
+				l.entries[key{e.PI, e.Frequency}] = e
+			}
+		}
+	}
+	return l
+}
+
+// Entries returns a copy of the log.
+func (l *Logger) Entries() []Entry {
+	rv := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		rv = append(rv, *e)
+	}
+	return rv
+}
+
+// Run sweeps the band forever, logging every station with RDS.
+func (l *Logger) Run() error {
+	return l.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (l *Logger) RunContext(ctx context.Context) error {
+	for {
+		if _, err := l.dev.Seek(1); err == si4703.ErrSeekFailed {
+			// an empty band, wait for it to open
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(l.Dwell):
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		status, err := l.dev.Status()
+		if err != nil {
+			return err
+		}
+		deadline := time.Now().Add(l.Dwell)
+		for time.Now().Before(deadline) {
+			if _, _, err := l.dev.ReadRDS(); err != nil {
+				return err
+			}
+			if data := l.dev.RDSData(); data.PI != 0 && len(data.ProgramService) == 8 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(l.dev.Timings().RDSPoll):
+			}
+		}
+		if err := l.Observe(time.Now(), status, l.dev.RDSData()); err != nil {
+			return err
+		}
+	}
+}
+
+// Observe records what was received on the current station at time now.
+// It can be used instead of Run when the application tunes by itself.
+func (l *Logger) Observe(now time.Time, status si4703.Status, data si4703.RDSData) error {
+	if data.PI == 0 {
+		return nil
+	}
+	k := key{data.PI, status.Frequency}
+	e, ok := l.entries[k]
+	if !ok {
+		e = &Entry{
+			PI:         data.PI,
+			Frequency:  status.Frequency,
+			FirstHeard: now,
+		}
+		l.entries[k] = e
+		if l.OnNew != nil {
+			l.OnNew(*e)
+		}
+	}
+	e.LastHeard = now
+	if status.RSSI > e.BestRSSI {
+		e.BestRSSI = status.RSSI
+	}
+	if data.ProgramService != "" {
+		e.PS = data.ProgramService
+	}
+	l.dirty = true
+	if ok && now.Sub(l.saved) < l.SaveInterval {
+		return nil
+	}
+	return l.Save(now)
+}
+
+// Save writes the log to storage if anything changed.
+func (l *Logger) Save(now time.Time) error {
+	if !l.dirty {
+		return nil
+	}
+	data, err := json.Marshal(l.Entries())
+	if err != nil {
+		return err
+	}
+	if err = l.storage.Store(StorageKey, data); err != nil {
+		return err
+	}
+	l.dirty = false
+	l.saved = now
+	return nil
+}
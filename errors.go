@@ -0,0 +1,51 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// RegisterError is returned when an operation fails, with the registers
+// that matter most for diagnosing it as they were last read. Get at it with
+// errors.As:
+//
+//	var re *si4703.RegisterError
+//	if errors.As(err, &re) {
+//		log.Println(re.Op, re.StatusRSSI, re.PowerCfg, re.Channel)
+//	}
+type RegisterError struct {
+	Op         string // the operation that failed, such as "seek"
+	Err        error
+	StatusRSSI uint16
+	PowerCfg   uint16
+	Channel    uint16
+	ReadChan   uint16
+}
+
+func (e *RegisterError) Error() string {
+	return "si4703: " + e.Op + ": " + e.Err.Error() +
+		" [STATUSRSSI=" + hex4(e.StatusRSSI) +
+		" POWERCFG=" + hex4(e.PowerCfg) +
+		" CHANNEL=" + hex4(e.Channel) +
+		" READCHAN=" + hex4(e.ReadChan) + "]"
+}
+
+func (e *RegisterError) Unwrap() error {
+	return e.Err
+}
+
+// registerError wraps err with the shadow registers.
+func (d *Device) registerError(op string, err error) error {
+	return &RegisterError{
+		Op:         op,
+		Err:        err,
+		StatusRSSI: d.registers[STATUSRSSI],
+		PowerCfg:   d.registers[POWERCFG],
+		Channel:    d.registers[CHANNEL],
+		ReadChan:   d.registers[READCHAN],
+	}
+}
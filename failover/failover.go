@@ -0,0 +1,208 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package failover keeps a programme playing from backup frequencies. When
+// the primary frequency is lost for good, it switches to the first backup
+// that is receivable, and while on a backup it briefly checks the primary
+// every ProbeInterval, switching back once the primary has recovered.
+//
+//	f, err := failover.New(&fm, 90900, 97300, 104100)
+//	f.OnEvent = func(e failover.Event) { ... }
+//	f.Run()
+package failover
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+	"github.com/mcilley/go-si4703/reception"
+)
+
+const (
+	DefaultProbeInterval = 5 * time.Minute
+	DefaultProbeDwell    = 300 * time.Millisecond
+)
+
+type Kind uint8
+
+const (
+	// KindFailover is a switch from the primary or a backup to a backup.
+	KindFailover Kind = iota
+	// KindRestore is a switch back to the primary.
+	KindRestore
+	// KindNoBackup means the signal was lost and no backup is receivable.
+	KindNoBackup
+)
+
+// Event reports a transition between frequencies.
+type Event struct {
+	Kind Kind
+	Time time.Time
+	From uint32 // kHz
+	To   uint32 // kHz, 0 for KindNoBackup
+}
+
+// Failover switches one device between a primary and backup frequencies.
+type Failover struct {
+	// Monitor decides when the signal is lost. Its thresholds may be
+	// adjusted; its OnEvent belongs to the failover.
+	Monitor *reception.Monitor
+	// ProbeInterval is how often the primary is checked while on a
+	// backup, and ProbeDwell how long it is listened to each time.
+	ProbeInterval time.Duration
+	ProbeDwell    time.Duration
+
+	OnEvent func(Event)
+
+	dev     *si4703.Device
+	primary uint32
+	backups []uint32
+	current uint32
+	probed  time.Time
+	// err is the first device error met handling a reception event,
+	// returned by Check
+	err error
+}
+
+// New returns a failover for the programme on primary, tuning it, with
+// backups tried in order. It fails if tuning the primary fails.
+func New(dev *si4703.Device, primary uint32, backups ...uint32) (*Failover, error) {
+	f := &Failover{
+		Monitor:       reception.New(dev),
+		ProbeInterval: DefaultProbeInterval,
+		ProbeDwell:    DefaultProbeDwell,
+		dev:           dev,
+		primary:       primary,
+		backups:       backups,
+		current:       primary,
+	}
+	f.Monitor.OnEvent = f.onReception
+	if err := dev.SetFrequency(primary); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SetBackups replaces the backup frequencies.
+func (f *Failover) SetBackups(backups ...uint32) {
+	f.backups = backups
+}
+
+// Current returns the frequency playing.
+func (f *Failover) Current() uint32 {
+	return f.current
+}
+
+// Run checks the signal every Monitor.Interval until the device fails.
+func (f *Failover) Run() error {
+	return f.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (f *Failover) RunContext(ctx context.Context) error {
+	for {
+		if err := f.Check(time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.Monitor.Interval):
+		}
+	}
+}
+
+// Check checks the signal at time now, failing over or restoring as
+// needed.
+func (f *Failover) Check(now time.Time) error {
+	err := f.Monitor.Check(now)
+	if err == nil {
+		err = f.err
+	}
+	f.err = nil
+	if err != nil {
+		return err
+	}
+	if f.current != f.primary && now.Sub(f.probed) >= f.ProbeInterval {
+		f.probed = now
+		return f.probePrimary(now)
+	}
+	return nil
+}
+
+func (f *Failover) onReception(e reception.Event) {
+	if !e.Lost {
+		return
+	}
+	from := f.current
+	for _, freq := range f.backups {
+		if freq == from {
+			continue
+		}
+		rssi, err := f.listen(freq)
+		if err != nil {
+			f.err = err
+			return
+		}
+		if rssi >= f.Monitor.MinRSSI {
+			f.current = freq
+			f.probed = e.Time
+			f.emit(Event{Kind: KindFailover, Time: e.Time, From: from, To: freq})
+			return
+		}
+	}
+	if err := f.dev.SetFrequency(from); err != nil {
+		f.err = err
+		return
+	}
+	f.emit(Event{Kind: KindNoBackup, Time: e.Time, From: from})
+}
+
+// probePrimary switches back to the primary if it has recovered.
+func (f *Failover) probePrimary(now time.Time) error {
+	muted, err := f.dev.Muted()
+	if err != nil {
+		return err
+	}
+	if err := f.dev.EnableMute(); err != nil {
+		return err
+	}
+	rssi, err := f.listen(f.primary)
+	if err != nil {
+		return err
+	}
+	if int(rssi) >= int(f.Monitor.MinRSSI)+int(f.Monitor.Hysteresis) {
+		from := f.current
+		f.current = f.primary
+		f.emit(Event{Kind: KindRestore, Time: now, From: from, To: f.primary})
+	} else if err := f.dev.SetFrequency(f.current); err != nil {
+		return err
+	}
+	if !muted {
+		return f.dev.DisableMute()
+	}
+	return nil
+}
+
+// listen tunes freq and returns its signal after ProbeDwell.
+func (f *Failover) listen(freq uint32) (uint8, error) {
+	if err := f.dev.SetFrequency(freq); err != nil {
+		return 0, err
+	}
+	time.Sleep(f.ProbeDwell)
+	status, err := f.dev.Status()
+	return status.RSSI, err
+}
+
+func (f *Failover) emit(e Event) {
+	if f.OnEvent != nil {
+		f.OnEvent(e)
+	}
+}
@@ -0,0 +1,358 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package follow keeps following a programme across frequencies when its
+// signal is lost, combining the alternative frequencies (AF) the station
+// broadcasts over RDS with a manual list of backup frequencies in one
+// policy. Candidates are tried source by source in Priority order; within
+// a source the strongest receivable candidate wins. AF candidates must
+// carry the programme's PI code, manual backups are trusted as they are.
+//
+// Sloppy broadcasters don't send AF lists. For them SourceScan seeks
+// through the band and takes a station as the same programme when its PI
+// code matches and its PS name is close to the programme's. The scan is
+// only done when the tuned station has no AF list.
+//
+// The choice itself is made by Policy.Choose, which only looks at
+// measured candidates, so a policy can be checked without a radio.
+//
+// By default the AF list is the one the device decodes from RDS group 0A,
+// so a follower left running switches to the strongest alternative
+// frequency whenever the signal drops below Monitor.MinRSSI, like a car
+// radio:
+//
+//	f := follow.New(&fm)
+//	f.Backups = []uint32{97300}
+//	f.OnEvent = func(e follow.Event) { ... }
+//	f.Run()
+package follow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+	"github.com/mcilley/go-si4703/reception"
+)
+
+// Source is where a candidate frequency came from.
+type Source uint8
+
+const (
+	SourceAF Source = iota
+	SourceBackup
+	SourceScan
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceAF:
+		return "af"
+	case SourceBackup:
+		return "backup"
+	default:
+		return "scan"
+	}
+}
+
+// DefaultDwell is how long each candidate is listened to, long enough to
+// receive a few RDS groups for the PI code.
+const DefaultDwell = 500 * time.Millisecond
+
+// Candidate is a frequency measured while looking for the programme.
+type Candidate struct {
+	Frequency uint32 // kHz
+	Source    Source
+	RSSI      uint8
+	PI        uint16 // 0 if none was received
+	PS        string
+}
+
+// Event reports a switch from one frequency to another, or that no
+// candidate was usable, in which case To is 0.
+type Event struct {
+	Time   time.Time
+	From   uint32
+	To     uint32
+	Source Source
+	PI     uint16
+}
+
+// Policy decides which candidate to switch to.
+type Policy struct {
+	// Priority is the order sources are tried in.
+	Priority []Source
+	// MinRSSI is the signal a candidate needs. Zero uses the monitor's.
+	MinRSSI uint8
+	// RegionalLock, like the REG setting of car radios, only accepts AFs
+	// with exactly the programme's PI code. With it off, regional
+	// variants of the programme are accepted too: PI codes that differ
+	// only in the area coverage nibble, when both are regional.
+	RegionalLock bool
+}
+
+// Follower follows the programme tuned on one device.
+type Follower struct {
+	// Monitor decides when the signal is lost. Its thresholds may be
+	// adjusted; its OnEvent belongs to the follower.
+	Monitor *reception.Monitor
+	Policy  Policy
+	// Backups are the manual backup frequencies in kHz.
+	Backups []uint32
+	// AF returns the alternative frequencies of the tuned station, in
+	// kHz. It defaults to the AF list decoded by the device; set it to
+	// nil to only use backups.
+	AF func() []uint32
+	// Dwell is how long each candidate is listened to.
+	Dwell time.Duration
+
+	OnEvent func(Event)
+
+	dev *si4703.Device
+	// err is the first device error met handling a reception event,
+	// returned by Check
+	err error
+}
+
+func New(dev *si4703.Device) *Follower {
+	f := &Follower{
+		Monitor: reception.New(dev),
+		Policy: Policy{
+			Priority:     []Source{SourceAF, SourceBackup},
+			RegionalLock: true,
+		},
+		AF: func() []uint32 {
+			return dev.RDSData().AF
+		},
+		Dwell: DefaultDwell,
+		dev:   dev,
+	}
+	f.Monitor.OnEvent = f.onReception
+	return f
+}
+
+// Run checks the signal every Monitor.Interval until the device fails.
+func (f *Follower) Run() error {
+	return f.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (f *Follower) RunContext(ctx context.Context) error {
+	for {
+		if err := f.Check(time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.Monitor.Interval):
+		}
+	}
+}
+
+// Check checks the signal at time now, following the programme elsewhere
+// if it was lost.
+func (f *Follower) Check(now time.Time) error {
+	err := f.Monitor.Check(now)
+	if err == nil {
+		err = f.err
+	}
+	f.err = nil
+	return err
+}
+
+// Choose picks the candidate to switch to for the programme with PI code
+// pi and station name ps: the strongest one of the first source in
+// priority order that has a candidate with at least MinRSSI, a matching
+// PI code if it is an AF, and a matching PI code and similar station name
+// if it was found by scanning.
+func (p Policy) Choose(candidates []Candidate, pi uint16, ps string) (Candidate, bool) {
+	for _, source := range p.Priority {
+		var best Candidate
+		found := false
+		for _, c := range candidates {
+			if c.Source != source || c.RSSI < p.MinRSSI {
+				continue
+			}
+			if source == SourceAF && pi != 0 && !MatchPI(pi, c.PI, p.RegionalLock) {
+				continue
+			}
+			if source == SourceScan && (pi == 0 || !MatchPI(pi, c.PI, p.RegionalLock) || !SimilarPS(ps, c.PS)) {
+				continue
+			}
+			if !found || c.RSSI > best.RSSI {
+				best, found = c, true
+			}
+		}
+		if found {
+			return best, true
+		}
+	}
+	return Candidate{}, false
+}
+
+// MatchPI reports whether a station with PI code candidate carries the
+// programme with PI code pi. Unless regionalLock is set, regional variants
+// match: the country, programme reference and network must agree, while
+// the area coverage nibble may differ if both codes are regional, 4 to F.
+func MatchPI(pi, candidate uint16, regionalLock bool) bool {
+	if pi == candidate {
+		return true
+	}
+	if regionalLock || pi&0xF0FF != candidate&0xF0FF {
+		return false
+	}
+	return pi>>8&0xF >= 4 && candidate>>8&0xF >= 4
+}
+
+// SimilarPS reports whether two station names are alike enough to belong
+// to the same programme: at least 6 of the 8 positions agree, ignoring
+// case. Names not received, empty, always agree, so that the PI code
+// decides alone.
+func SimilarPS(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	a = strings.ToUpper(a + "        ")[:8]
+	b = strings.ToUpper(b + "        ")[:8]
+	same := 0
+	for i := 0; i < 8; i++ {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return same >= 6
+}
+
+func (f *Follower) onReception(e reception.Event) {
+	if !e.Lost {
+		return
+	}
+	if err := f.follow(e); err != nil {
+		f.err = err
+	}
+}
+
+// follow looks for the programme lost in e elsewhere and switches to it.
+func (f *Follower) follow(e reception.Event) error {
+	from := e.Station.Frequency
+	// the AF list is forgotten once the first candidate is tuned
+	afs := f.frequencies(SourceAF)
+	var candidates []Candidate
+	for _, source := range f.Policy.Priority {
+		if source == SourceScan {
+			if len(afs) == 0 {
+				found, err := f.scan(from)
+				if err != nil {
+					return err
+				}
+				candidates = append(candidates, found...)
+			}
+			continue
+		}
+		freqs := afs
+		if source != SourceAF {
+			freqs = f.frequencies(source)
+		}
+		for _, freq := range freqs {
+			if freq != from {
+				c, err := f.measure(freq, source)
+				if err != nil {
+					return err
+				}
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	policy := f.Policy
+	if policy.MinRSSI == 0 {
+		policy.MinRSSI = f.Monitor.MinRSSI
+	}
+	c, ok := policy.Choose(candidates, e.Station.PI, e.Station.ProgramService)
+	if !ok {
+		if err := f.dev.SetFrequency(from); err != nil {
+			return err
+		}
+		f.emit(Event{Time: e.Time, From: from})
+		return nil
+	}
+	if err := f.dev.SetFrequency(c.Frequency); err != nil {
+		return err
+	}
+	f.emit(Event{Time: e.Time, From: from, To: c.Frequency, Source: c.Source, PI: c.PI})
+	return nil
+}
+
+func (f *Follower) frequencies(source Source) []uint32 {
+	switch source {
+	case SourceAF:
+		if f.AF != nil {
+			return f.AF()
+		}
+		return nil
+	case SourceBackup:
+		return f.Backups
+	default:
+		return nil
+	}
+}
+
+// scan seeks once around the band from from, measuring every station.
+func (f *Follower) scan(from uint32) ([]Candidate, error) {
+	var rv []Candidate
+	for {
+		freq, err := f.dev.Seek(1)
+		if err == si4703.ErrSeekFailed {
+			return rv, nil
+		} else if err != nil {
+			return rv, err
+		}
+		if freq == from || len(rv) > 0 && freq == rv[0].Frequency {
+			return rv, nil
+		}
+		c, err := f.listen(freq, SourceScan)
+		if err != nil {
+			return rv, err
+		}
+		rv = append(rv, c)
+	}
+}
+
+// measure tunes freq and listens to it.
+func (f *Follower) measure(freq uint32, source Source) (Candidate, error) {
+	if err := f.dev.SetFrequency(freq); err != nil {
+		return Candidate{}, err
+	}
+	return f.listen(freq, source)
+}
+
+// listen reads RDS from the tuned station for Dwell.
+func (f *Follower) listen(freq uint32, source Source) (Candidate, error) {
+	c := Candidate{Frequency: freq, Source: source}
+	for deadline := time.Now().Add(f.Dwell); time.Now().Before(deadline); {
+		if _, _, err := f.dev.ReadRDS(); err != nil {
+			return c, err
+		}
+		time.Sleep(f.dev.Timings().RDSPoll)
+	}
+	data := f.dev.RDSData()
+	c.PI = data.PI
+	c.PS = data.ProgramService
+	status, err := f.dev.Status()
+	c.RSSI = status.RSSI
+	return c, err
+}
+
+func (f *Follower) emit(e Event) {
+	if f.OnEvent != nil {
+		f.OnEvent(e)
+	}
+}
@@ -0,0 +1,198 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package follow
+
+import "testing"
+
+func TestChoose(t *testing.T) {
+	allSources := []Source{SourceAF, SourceBackup, SourceScan}
+	tests := []struct {
+		name       string
+		policy     Policy
+		candidates []Candidate
+		pi         uint16
+		ps         string
+		want       uint32 // 0 for no choice
+	}{
+		{
+			name:   "no candidates",
+			policy: Policy{Priority: allSources},
+		},
+		{
+			name:   "strongest of first source wins",
+			policy: Policy{Priority: allSources},
+			candidates: []Candidate{
+				{Frequency: 88100, Source: SourceBackup, RSSI: 60},
+				{Frequency: 89100, Source: SourceAF, RSSI: 20, PI: 0xC201},
+				{Frequency: 90100, Source: SourceAF, RSSI: 30, PI: 0xC201},
+			},
+			pi:   0xC201,
+			want: 90100,
+		},
+		{
+			name:   "priority order is the policy's",
+			policy: Policy{Priority: []Source{SourceBackup, SourceAF}},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceAF, RSSI: 60, PI: 0xC201},
+				{Frequency: 88100, Source: SourceBackup, RSSI: 20},
+			},
+			pi:   0xC201,
+			want: 88100,
+		},
+		{
+			name:   "sources not in priority are ignored",
+			policy: Policy{Priority: []Source{SourceAF}},
+			candidates: []Candidate{
+				{Frequency: 88100, Source: SourceBackup, RSSI: 60},
+			},
+			pi: 0xC201,
+		},
+		{
+			name:   "below RSSI floor falls through to next source",
+			policy: Policy{Priority: allSources, MinRSSI: 25},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceAF, RSSI: 24, PI: 0xC201},
+				{Frequency: 88100, Source: SourceBackup, RSSI: 25},
+			},
+			pi:   0xC201,
+			want: 88100,
+		},
+		{
+			name:   "nothing above RSSI floor",
+			policy: Policy{Priority: allSources, MinRSSI: 40},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceAF, RSSI: 39, PI: 0xC201},
+				{Frequency: 88100, Source: SourceBackup, RSSI: 10},
+			},
+			pi: 0xC201,
+		},
+		{
+			name:   "AF with other PI rejected",
+			policy: Policy{Priority: allSources},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceAF, RSSI: 60, PI: 0xD301},
+				{Frequency: 90100, Source: SourceAF, RSSI: 20, PI: 0xC201},
+			},
+			pi:   0xC201,
+			want: 90100,
+		},
+		{
+			name:   "AF accepted without a PI to compare",
+			policy: Policy{Priority: allSources},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceAF, RSSI: 60, PI: 0xD301},
+			},
+			want: 89100,
+		},
+		{
+			name:   "regional AF accepted without lock",
+			policy: Policy{Priority: allSources},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceAF, RSSI: 60, PI: 0xC501},
+			},
+			pi:   0xC401,
+			want: 89100,
+		},
+		{
+			name:   "regional AF rejected with lock",
+			policy: Policy{Priority: allSources, RegionalLock: true},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceAF, RSSI: 60, PI: 0xC501},
+				{Frequency: 90100, Source: SourceAF, RSSI: 20, PI: 0xC401},
+			},
+			pi:   0xC401,
+			want: 90100,
+		},
+		{
+			name:   "scan needs a PI",
+			policy: Policy{Priority: []Source{SourceScan}},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceScan, RSSI: 60, PI: 0xC201, PS: "BBC R4"},
+			},
+		},
+		{
+			name:   "scan needs a similar name",
+			policy: Policy{Priority: []Source{SourceScan}},
+			candidates: []Candidate{
+				{Frequency: 89100, Source: SourceScan, RSSI: 60, PI: 0xC201, PS: "CLASSIC"},
+				{Frequency: 90100, Source: SourceScan, RSSI: 20, PI: 0xC201, PS: "BBC R4 "},
+			},
+			pi:   0xC201,
+			ps:   "BBC R4",
+			want: 90100,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := test.policy.Choose(test.candidates, test.pi, test.ps)
+			if ok != (test.want != 0) || got.Frequency != test.want {
+				t.Errorf("got %d %t, want %d", got.Frequency, ok, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchPI(t *testing.T) {
+	tests := []struct {
+		pi, candidate uint16
+		regionalLock  bool
+		want          bool
+	}{
+		{0xC201, 0xC201, false, true},
+		{0xC201, 0xC201, true, true},
+		// local, international, national and supra-regional, 0 to 3,
+		// never match a variant
+		{0xC001, 0xC101, false, false},
+		{0xC201, 0xC301, false, false},
+		{0xC301, 0xC401, false, false},
+		// regional, 4 to F, match each other unless locked
+		{0xC401, 0xC501, false, true},
+		{0xC401, 0xCF01, false, true},
+		{0xC401, 0xCF01, true, false},
+		// the rest of the code must agree
+		{0xC401, 0xD501, false, false},
+		{0xC401, 0xC502, false, false},
+		{0xC401, 0xC511, false, false},
+	}
+	for _, test := range tests {
+		if got := MatchPI(test.pi, test.candidate, test.regionalLock); got != test.want {
+			t.Errorf("MatchPI(%04X, %04X, %t) = %t, want %t", test.pi, test.candidate, test.regionalLock, got, test.want)
+		}
+	}
+}
+
+func TestSimilarPS(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"", "BBC R4", true},
+		{"BBC R4", "", true},
+		{"BBC R4", "BBC R4", true},
+		{"BBC R4", "bbc r4", true},
+		// short names are padded with spaces
+		{"BBC R4", "BBC R4  ", true},
+		{"R4", "R4", true},
+		{"R4", "R5", true},
+		{"R4", "XY", true},
+		{"R4", "XYZ", false},
+		// 6 of 8 positions must agree
+		{"RADIO 1", "RADIO 2", true},
+		{"RADIO 1", "RADIX 2", true},
+		{"RADIO 1", "RODIX 2", false},
+		{"BBC R4", "CLASSIC", false},
+	}
+	for _, test := range tests {
+		if got := SimilarPS(test.a, test.b); got != test.want {
+			t.Errorf("SimilarPS(%q, %q) = %t, want %t", test.a, test.b, got, test.want)
+		}
+	}
+}
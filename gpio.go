@@ -0,0 +1,54 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "errors"
+
+var ErrInvalidGPIO = errors.New("si4703: invalid GPIO, must be 1 to 3")
+
+// GPIOMode is the setting of one of the chip's GPIO pins in SYSCONFIG1.
+type GPIOMode uint8
+
+const (
+	GPIOHighZ GPIOMode = 0 // the power-up default
+	GPIOLow   GPIOMode = 2
+	GPIOHigh  GPIOMode = 3
+)
+
+// GPIO3 function selected by SYSCONFIG1[5:4]
+const gpio3StereoIndicator = 0x1
+
+// SetStereoIndicatorPin makes the chip drive its GPIO3 pin high while it
+// receives stereo and low in mono, so a stereo LED on the front panel
+// works without the MCU.
+func (d *Device) SetStereoIndicatorPin() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("stereo indicator", func() {
+		d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1]&^(0x3<<GPIO3) | gpio3StereoIndicator<<GPIO3
+	})
+}
+
+// SetGPIO drives the chip's GPIO1, GPIO2 or GPIO3 pin, given as 1 to 3,
+// high or low, or leaves it high impedance. It can light a tuned LED
+// straight from the tuner. Driving GPIO2 or GPIO3 replaces the interrupt
+// or stereo indicator function set by SetInterrupts or
+// SetStereoIndicatorPin.
+func (d *Device) SetGPIO(pin int, mode GPIOMode) error {
+	if pin < 1 || pin > 3 {
+		return ErrInvalidGPIO
+	}
+	shift := GPIO1 + 2*uint16(pin-1)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("gpio", func() {
+		d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1]&^(0x3<<shift) | uint16(mode&0x3)<<shift
+	})
+}
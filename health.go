@@ -0,0 +1,73 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnhealthy is returned by HealthCheck when the chip answered but one of
+// the checks failed.
+var ErrUnhealthy = errors.New("si4703: health check failed")
+
+// manufacturer ID of Silicon Labs in DEVICEID
+const manufacturerSiLabs = 0x242
+
+// Health is the result of a HealthCheck.
+type Health struct {
+	DeviceInfo DeviceInfo
+	// DeviceID is true if the chip identifies as an Si4702/03.
+	DeviceID bool
+	// PoweredUp is true if the chip is enabled.
+	PoweredUp bool
+	// Oscillator is true if the crystal oscillator is enabled.
+	Oscillator bool
+
+	// state of the tuned station, informational only
+	RSSI            uint8
+	Stereo          bool
+	RDSSynchronized bool
+	RDSQuality      uint8
+}
+
+// OK reports whether all checks passed.
+func (h Health) OK() bool {
+	return h.DeviceID && h.PoweredUp && h.Oscillator
+}
+
+// HealthCheck reads all registers and checks that the chip answers on the
+// bus, identifies itself correctly, is powered up and has its oscillator
+// running, for periodic self tests of unattended receivers. A bus failure
+// returns a RegisterError, a failed check ErrUnhealthy along with the
+// details in Health.
+func (d *Device) HealthCheck() (Health, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return Health{}, d.registerError("health check", err)
+	}
+	info := d.deviceInfo()
+	status := d.registers[STATUSRSSI]
+	h := Health{
+		DeviceInfo:      info,
+		DeviceID:        info.PartNumber == 1 && info.Manufacturer == manufacturerSiLabs,
+		PoweredUp:       d.registers[POWERCFG]&(1<<ENABLE) != 0 && d.registers[POWERCFG]&(1<<DISABLE) == 0,
+		Oscillator:      d.registers[TEST1]&(1<<XOSCEN) != 0,
+		RSSI:            d.rssi(status),
+		Stereo:          status>>STEREO&0x1 == 1,
+		RDSSynchronized: status>>RDSS&0x1 == 1,
+		RDSQuality:      d.rdsQuality.percent(time.Now()),
+	}
+	if !h.OK() {
+		return h, ErrUnhealthy
+	}
+	return h, nil
+}
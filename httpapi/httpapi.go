@@ -0,0 +1,325 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package httpapi serves an Si4703 over HTTP, turning a Raspberry Pi or
+// similar host into a network radio. It offers a small JSON API and an
+// embedded single page UI with a tuning dial, presets, RDS display and
+// signal meter at "/".
+//
+//	GET  /api/status                 tuner and RDS state
+//	GET  /api/presets                preset frequencies in kHz
+//	GET  /api/history?pi=C201        now playing history, pi optional
+//	POST /api/tune?frequency=90900   tune, frequency in kHz
+//	POST /api/seek?dir=up|down       seek to the next station
+//	POST /api/volume?level=0..15     set the volume
+//	POST /api/mute?on=true|false     mute or unmute
+//
+//	srv := httpapi.New(&fm)
+//	srv.Presets = []uint32{88100, 90900, 93500}
+//	srv.Name = "Kitchen Radio" // advertise over mDNS
+//	srv.History = nowplaying.New(&fm, 100)
+//	log.Fatal(srv.ListenAndServe(":8080"))
+package httpapi
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+	"github.com/mcilley/go-si4703/nowplaying"
+)
+
+//go:embed ui
+var ui embed.FS
+
+// Status is the JSON document returned by /api/status.
+type Status struct {
+	Frequency uint32 `json:"frequency"`
+	RSSI      uint8  `json:"rssi"`
+	Stereo    bool   `json:"stereo"`
+	RDSSync   bool   `json:"rds_sync"`
+	Volume    uint16 `json:"volume"`
+	Muted     bool   `json:"muted"`
+	PI        uint16 `json:"pi"`
+	PTY       uint8  `json:"pty"`
+	PTYName   string `json:"pty_name"`
+	PS        string `json:"ps"`
+	RadioText string `json:"radiotext"`
+
+	// Frequency formatted with the decimals the channel grid needs, and
+	// the band limits and channel spacing in kHz
+	MHz        string `json:"mhz"`
+	BandBottom uint32 `json:"band_bottom"`
+	BandTop    uint32 `json:"band_top"`
+	Spacing    uint32 `json:"spacing"`
+}
+
+// Server exposes a device over HTTP. All access to the device goes through
+// the server's lock, including the background RDS reader.
+type Server struct {
+	// Presets are offered by the UI as one-touch buttons, in kHz.
+	Presets []uint32
+	// Name, if set, is the instance name ListenAndServe advertises the
+	// server under with mDNS.
+	Name string
+	// History, if set, is fed by the RDS reader and served at
+	// /api/history.
+	History *nowplaying.History
+
+	mu  sync.Mutex
+	dev *si4703.Device
+	mux *http.ServeMux
+}
+
+func New(dev *si4703.Device) *Server {
+	s := &Server{
+		dev: dev,
+		mux: http.NewServeMux(),
+	}
+	static, _ := fs.Sub(ui, "ui")
+	s.mux.Handle("/", http.FileServer(http.FS(static)))
+	s.mux.HandleFunc("/api/status", s.handleStatus)
+	s.mux.HandleFunc("/api/presets", s.handlePresets)
+	s.mux.HandleFunc("/api/history", s.handleHistory)
+	s.mux.HandleFunc("/api/tune", s.post(s.handleTune))
+	s.mux.HandleFunc("/api/seek", s.post(s.handleSeek))
+	s.mux.HandleFunc("/api/volume", s.post(s.handleVolume))
+	s.mux.HandleFunc("/api/mute", s.post(s.handleMute))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Do runs f with exclusive use of the device and returns its error.
+func (s *Server) Do(f func(dev *si4703.Device) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return f(s.dev)
+}
+
+// ReadRDS reads RDS groups every interval, forever, so the RDS fields of
+// the status stay current. Reads that fail are retried at the next
+// interval.
+func (s *Server) ReadRDS(interval time.Duration) {
+	s.ReadRDSContext(context.Background(), interval)
+}
+
+// ReadRDSContext is ReadRDS until ctx is done, when it returns ctx.Err().
+func (s *Server) ReadRDSContext(ctx context.Context, interval time.Duration) error {
+	for {
+		s.Do(func(dev *si4703.Device) error {
+			_, ok, err := dev.ReadRDS()
+			if err != nil || !ok || s.History == nil {
+				return err
+			}
+			status, err := dev.Status()
+			if err != nil {
+				return err
+			}
+			s.History.Observe(time.Now(), status, dev.RDSData())
+			return nil
+		})
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ListenAndServe starts the RDS reader, advertises the server if Name is
+// set and serves HTTP on addr.
+func (s *Server) ListenAndServe(addr string) error {
+	return s.ListenAndServeContext(context.Background(), addr)
+}
+
+// ListenAndServeContext is ListenAndServe until ctx is done, when the RDS
+// reader stops, the server is closed and ctx.Err() is returned.
+func (s *Server) ListenAndServeContext(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if s.Name != "" {
+		stop, err := s.Advertise(s.Name, ln.Addr().(*net.TCPAddr).Port)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		defer stop()
+	}
+	srv := &http.Server{Handler: s}
+	go s.ReadRDSContext(ctx, s.dev.Timings().RDSPoll)
+	defer context.AfterFunc(ctx, func() { srv.Close() })()
+	err = srv.Serve(ln)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func (s *Server) status() (Status, error) {
+	var rv Status
+	err := s.Do(func(dev *si4703.Device) error {
+		status, err := dev.Status()
+		if err != nil {
+			return err
+		}
+		volume, err := dev.Volume()
+		if err != nil {
+			return err
+		}
+		muted, err := dev.Muted()
+		if err != nil {
+			return err
+		}
+		data := dev.RDSData()
+		band, spacing := dev.Band(), dev.Spacing()
+		rv = Status{
+			Frequency: status.Frequency,
+			RSSI:      status.RSSI,
+			Stereo:    status.Stereo,
+			RDSSync:   status.RDSSynchronized,
+			Volume:    volume,
+			Muted:     muted,
+			PI:        data.PI,
+			PTY:       data.ProgramType,
+			PTYName:   dev.ProgramTypeName(),
+			PS:        data.ProgramService,
+			RadioText: data.RadioText,
+
+			MHz:        spacing.FormatMHz(status.Frequency),
+			BandBottom: band.Bottom(),
+			BandTop:    band.Top(),
+			Spacing:    spacing.KHz(),
+		}
+		return nil
+	})
+	return rv, err
+}
+
+// writeStatus replies with the current status, or an error if the device
+// cannot be read.
+func (s *Server) writeStatus(w http.ResponseWriter) {
+	status, err := s.status()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.writeStatus(w)
+}
+
+func (s *Server) handlePresets(w http.ResponseWriter, r *http.Request) {
+	presets := s.Presets
+	if presets == nil {
+		presets = []uint32{}
+	}
+	writeJSON(w, presets)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	entries := []nowplaying.Entry{}
+	if s.History != nil {
+		if pi := r.FormValue("pi"); pi != "" {
+			v, err := strconv.ParseUint(pi, 16, 16)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			entries = append(entries, s.History.Station(uint16(v))...)
+		} else {
+			entries = append(entries, s.History.Entries()...)
+		}
+	}
+	writeJSON(w, entries)
+}
+
+// post restricts a handler to POST and replies with the new status.
+// Device failures are server errors, anything else is the request's fault.
+func (s *Server) post(h func(r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := h(r); err != nil {
+			code := http.StatusBadRequest
+			var re *si4703.RegisterError
+			if errors.As(err, &re) {
+				code = http.StatusInternalServerError
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		s.writeStatus(w)
+	}
+}
+
+func (s *Server) handleTune(r *http.Request) error {
+	khz, err := strconv.ParseUint(r.FormValue("frequency"), 10, 32)
+	if err != nil {
+		return err
+	}
+	return s.Do(func(dev *si4703.Device) error {
+		return dev.SetFrequency(uint32(khz))
+	})
+}
+
+func (s *Server) handleSeek(r *http.Request) error {
+	var dir byte
+	if r.FormValue("dir") != "down" {
+		dir = 1
+	}
+	return s.Do(func(dev *si4703.Device) error {
+		_, err := dev.Seek(dir)
+		return err
+	})
+}
+
+func (s *Server) handleVolume(r *http.Request) error {
+	level, err := strconv.ParseUint(r.FormValue("level"), 10, 16)
+	if err != nil {
+		return err
+	}
+	return s.Do(func(dev *si4703.Device) error {
+		return dev.SetVolume(uint16(level))
+	})
+}
+
+func (s *Server) handleMute(r *http.Request) error {
+	on, err := strconv.ParseBool(r.FormValue("on"))
+	if err != nil {
+		return err
+	}
+	return s.Do(func(dev *si4703.Device) error {
+		if on {
+			return dev.EnableMute()
+		}
+		return dev.DisableMute()
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,34 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package httpapi
+
+import (
+	"github.com/grandcat/zeroconf"
+)
+
+// ServiceType is the DNS-SD service the radio is advertised as: a web
+// server with an "_si4703" subtype companion apps can browse for.
+const ServiceType = "_http._tcp,_si4703"
+
+// Advertise announces the server on the local network with mDNS/DNS-SD
+// under the instance name, for example "Kitchen Radio". The returned
+// function withdraws the announcement.
+func (s *Server) Advertise(name string, port int) (stop func(), err error) {
+	txt := []string{
+		"path=/",
+		"api=/api",
+		"model=si4703",
+	}
+	server, err := zeroconf.Register(name, ServiceType, "local.", port, txt, nil)
+	if err != nil {
+		return nil, err
+	}
+	return server.Shutdown, nil
+}
@@ -0,0 +1,100 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// GPIO2 function selected by SYSCONFIG1[3:2]
+const gpio2Interrupt = 0x1
+
+// SetInterrupts routes seek/tune complete (stc) and RDS ready (rds)
+// interrupts to the chip's GPIO2 pin, which pulses low for 5 ms on each.
+// Connect the pin to the MCU and call Interrupt from its edge handler, or
+// use ListenInterrupts on tinygo, and run ServeInterrupts if rds is
+// enabled.
+//
+// Both interrupts share the one pin, so every interrupt is handled by
+// reading the status once and acting on all the flags set: a waiting tune
+// or seek completes and a ready RDS group is decoded.
+func (d *Device) SetInterrupts(stc, rds bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.modify("interrupts", func() {
+		r := d.registers[SYSCONFIG1] &^ (1<<STCIEN | 1<<RDSIEN | 0x3<<GPIO2)
+		if stc {
+			r |= 1 << STCIEN
+		}
+		if rds {
+			r |= 1 << RDSIEN
+		}
+		if stc || rds {
+			r |= gpio2Interrupt << GPIO2
+		}
+		d.registers[SYSCONFIG1] = r
+	})
+	if err != nil {
+		return err
+	}
+	d.irqSTC = stc
+	d.irqRDS = rds
+	return nil
+}
+
+// Interrupt tells the device that GPIO2 signalled. It does not block or
+// touch the bus, so it is safe to call from an interrupt handler.
+func (d *Device) Interrupt() {
+	select {
+	case d.irq <- struct{}{}:
+	default:
+	}
+}
+
+// ServeInterrupts handles interrupts signalled with Interrupt while no
+// tune or seek is waiting for them, decoding RDS groups as they arrive.
+// It runs until reading the chip fails and returns that error.
+//
+// While a tune or seek waits for STC, interrupts are handed to it instead:
+// it holds the device, reads the status and decodes a ready RDS group
+// itself, so no event is lost whichever of the two takes the interrupt.
+func (d *Device) ServeInterrupts() error {
+	for range d.irq {
+		if d.stcWaiting.Load() {
+			select {
+			case d.stcIRQ <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		if err := d.serveInterrupt(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serveInterrupt handles one interrupt.
+func (d *Device) serveInterrupt() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.dispatch(); err != nil {
+		return d.registerError("read rds", err)
+	}
+	return nil
+}
+
+// dispatch reads the status once and decodes a ready RDS group if RDS
+// interrupts are enabled. Callers look at the registers for anything else.
+func (d *Device) dispatch() error {
+	if err := d.readRegisters(); err != nil {
+		return err
+	}
+	if d.irqRDS {
+		d.receiveRDS()
+	}
+	return nil
+}
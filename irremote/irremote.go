@@ -0,0 +1,149 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+//go:build tinygo
+
+// Package irremote maps infrared remote control keys to tuner actions.
+//
+// Codes can come from an existing IR driver, passed to Handle, or be decoded
+// here from the raw output of a demodulating receiver such as a TSOP38238
+// connected to an interrupt capable pin.
+//
+//	r := irremote.New()
+//	r.Bind(irremote.Key{irremote.NEC, 0x00, 0x18}, buttons.SeekUp(&fm), false)
+//	r.Bind(irremote.Key{irremote.NEC, 0x00, 0x52}, buttons.SeekDown(&fm), false)
+//	r.Bind(irremote.Key{irremote.NEC, 0x00, 0x15}, buttons.VolumeUp(&fm), true)
+//	r.Bind(irremote.Key{irremote.NEC, 0x00, 0x07}, buttons.VolumeDown(&fm), true)
+//	r.Listen(machine.GP16)
+//	for {
+//		r.Poll()
+//		time.Sleep(10 * time.Millisecond)
+//	}
+package irremote
+
+import (
+	"machine"
+	"sync/atomic"
+	"time"
+)
+
+type Protocol uint8
+
+const (
+	NEC Protocol = iota
+	RC5
+)
+
+// Key identifies a button on a remote control.
+type Key struct {
+	Protocol Protocol
+	Address  uint16
+	Command  uint16
+}
+
+// Code is a single decoded transmission. Repeat is set when the button is
+// being held down rather than pressed again.
+type Code struct {
+	Key
+	Repeat bool
+}
+
+type binding struct {
+	action func() error
+	repeat bool
+}
+
+// Remote dispatches decoded codes to the actions bound in its keymap.
+type Remote struct {
+	// OnError, if set, is called with the error of each failed action.
+	OnError func(error)
+
+	keymap map[Key]binding
+
+	pin   machine.Pin
+	last  time.Time
+	edges [64]edge
+	head  uint32 // written by the interrupt handler
+	tail  uint32
+	nec   necDecoder
+	rc5   rc5Decoder
+}
+
+// edge is the length of one mark (IR carrier present) or space.
+type edge struct {
+	mark bool
+	us   uint32
+}
+
+func New() *Remote {
+	return &Remote{
+		keymap: make(map[Key]binding),
+	}
+}
+
+// Bind runs action when key is pressed. If repeat is true the action also
+// runs while the key is held, which suits volume keys.
+func (r *Remote) Bind(key Key, action func() error, repeat bool) {
+	r.keymap[key] = binding{action: action, repeat: repeat}
+}
+
+// Handle runs the action bound to a decoded code, if any.
+func (r *Remote) Handle(c Code) {
+	b, ok := r.keymap[c.Key]
+	if !ok || (c.Repeat && !b.repeat) {
+		return
+	}
+	if err := b.action(); err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// Listen decodes NEC and RC5 transmissions from an active low IR receiver
+// on pin. Edges are only recorded in the interrupt handler, call Poll from
+// the main loop to decode them and run the actions.
+func (r *Remote) Listen(pin machine.Pin) error {
+	r.pin = pin
+	r.last = time.Now()
+	pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return pin.SetInterrupt(machine.PinToggle, r.interrupt)
+}
+
+func (r *Remote) interrupt(machine.Pin) {
+	now := time.Now()
+	us := uint32(now.Sub(r.last) / time.Microsecond)
+	r.last = now
+	head := atomic.LoadUint32(&r.head)
+	// the receiver pulls the pin low while it sees the carrier, so a rising
+	// edge ends a mark
+	r.edges[head%uint32(len(r.edges))] = edge{mark: r.pin.Get(), us: us}
+	atomic.StoreUint32(&r.head, head+1)
+}
+
+// Poll decodes the edges recorded since the last call.
+func (r *Remote) Poll() {
+	head := atomic.LoadUint32(&r.head)
+	if head-r.tail > uint32(len(r.edges)) {
+		// overrun, drop what we missed
+		r.tail = head - uint32(len(r.edges))
+	}
+	for ; r.tail != head; r.tail++ {
+		e := r.edges[r.tail%uint32(len(r.edges))]
+		if c, ok := r.nec.edge(e); ok {
+			r.Handle(c)
+		}
+		if c, ok := r.rc5.edge(e); ok {
+			r.Handle(c)
+		}
+	}
+}
+
+// near reports whether a measured duration is within 25% of the nominal one.
+func near(us, nominal uint32) bool {
+	return us > nominal-nominal/4 && us < nominal+nominal/4
+}
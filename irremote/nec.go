@@ -0,0 +1,102 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+//go:build tinygo
+
+package irremote
+
+// NEC timings in microseconds
+const (
+	necLeaderMark  = 9000
+	necLeaderSpace = 4500
+	necRepeatSpace = 2250
+	necBitMark     = 562
+	necZeroSpace   = 562
+	necOneSpace    = 1687
+)
+
+type necState uint8
+
+const (
+	necIdle necState = iota
+	necLeader
+	necMark
+	necSpace
+)
+
+// necDecoder decodes the NEC protocol: a 9ms leader, 32 pulse distance
+// coded bits (address, inverted address, command, inverted command, LSB
+// first) and short repeat frames while the key is held.
+type necDecoder struct {
+	state necState
+	bits  uint32
+	n     int
+	last  Key
+	valid bool
+}
+
+func (d *necDecoder) edge(e edge) (Code, bool) {
+	switch d.state {
+	case necIdle:
+		if e.mark && near(e.us, necLeaderMark) {
+			d.state = necLeader
+		}
+	case necLeader:
+		d.state = necIdle
+		if e.mark {
+			break
+		}
+		if near(e.us, necLeaderSpace) {
+			d.state = necMark
+			d.bits = 0
+			d.n = 0
+		} else if near(e.us, necRepeatSpace) && d.valid {
+			return Code{Key: d.last, Repeat: true}, true
+		}
+	case necMark:
+		d.state = necIdle
+		if e.mark && near(e.us, necBitMark) {
+			if d.n == 32 {
+				return d.frame()
+			}
+			d.state = necSpace
+		}
+	case necSpace:
+		d.state = necIdle
+		if e.mark {
+			break
+		}
+		if near(e.us, necOneSpace) {
+			d.bits |= 1 << uint(d.n)
+		} else if !near(e.us, necZeroSpace) {
+			break
+		}
+		d.n++
+		d.state = necMark
+	}
+	return Code{}, false
+}
+
+// frame checks the inverted command copy of a complete frame. Extended NEC
+// remotes use all 16 address bits, so the address is only reduced to 8
+// bits when its inverted copy matches.
+func (d *necDecoder) frame() (Code, bool) {
+	cmd := byte(d.bits >> 16)
+	if cmd != ^byte(d.bits>>24) {
+		d.valid = false
+		return Code{}, false
+	}
+	addr := uint16(d.bits)
+	if byte(addr) == ^byte(addr>>8) {
+		addr &= 0xFF
+	}
+	d.last = Key{Protocol: NEC, Address: addr, Command: uint16(cmd)}
+	d.valid = true
+	return Code{Key: d.last}, true
+}
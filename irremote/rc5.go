@@ -0,0 +1,89 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+//go:build tinygo
+
+package irremote
+
+// RC5 half bit time in microseconds
+const rc5Half = 889
+
+// rc5Bits is the frame length: two start bits, toggle, 5 address and 6
+// command bits.
+const rc5Bits = 14
+
+// rc5Decoder decodes Philips RC5 Manchester coding, where a one is a space
+// followed by a mark. The frame is collected as a sequence of half bits;
+// the first space is indistinguishable from idle so it is implied.
+type rc5Decoder struct {
+	halves [rc5Bits * 2]bool
+	n      int
+	toggle uint16
+	seen   bool
+}
+
+func (d *rc5Decoder) edge(e edge) (Code, bool) {
+	count := 0
+	switch {
+	case near(e.us, rc5Half):
+		count = 1
+	case near(e.us, 2*rc5Half):
+		count = 2
+	}
+	if d.n == 0 {
+		// a frame starts with the mark of the first start bit
+		if !e.mark || count == 0 {
+			return Code{}, false
+		}
+		d.halves[0] = false
+		d.n = 1
+	}
+	if count == 0 {
+		d.n = 0
+		return Code{}, false
+	}
+	for i := 0; i < count && d.n < len(d.halves); i++ {
+		d.halves[d.n] = e.mark
+		d.n++
+	}
+	// a frame ending in a zero finishes with a space that merges into idle
+	if d.n == len(d.halves)-1 && d.halves[d.n-1] {
+		d.halves[d.n] = false
+		d.n++
+	}
+	if d.n < len(d.halves) {
+		return Code{}, false
+	}
+	d.n = 0
+	return d.frame()
+}
+
+func (d *rc5Decoder) frame() (Code, bool) {
+	var bits uint16
+	for i := 0; i < rc5Bits; i++ {
+		first, second := d.halves[2*i], d.halves[2*i+1]
+		if first == second {
+			return Code{}, false
+		}
+		bits <<= 1
+		if second {
+			bits |= 1
+		}
+	}
+	// the second start bit is the inverted bit 6 of the command in RC5X
+	cmd := bits&0x3F | (^bits>>12&0x1)<<6
+	toggle := bits >> 11 & 0x1
+	repeat := d.seen && toggle == d.toggle
+	d.toggle = toggle
+	d.seen = true
+	return Code{
+		Key:    Key{Protocol: RC5, Address: bits >> 6 & 0x1F, Command: cmd},
+		Repeat: repeat,
+	}, true
+}
@@ -0,0 +1,193 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package kiosk is for installations that must just play, such as museums
+// and shops. On start it tunes the primary station, checks that it is
+// actually received and otherwise walks an ordered list of fallback
+// stations. It then keeps watching the signal and, whenever the station
+// playing is lost, starts over from the primary.
+//
+//	k, err := kiosk.Start(&fm, kiosk.Config{
+//		Primary:   90900,
+//		Fallbacks: []uint32{93500, 101100},
+//		Volume:    10,
+//	})
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+	"github.com/mcilley/go-si4703/reception"
+)
+
+// ErrNoStation is returned by Start when neither the primary nor any
+// fallback station is receivable. Monitoring continues regardless.
+var ErrNoStation = errors.New("kiosk: no station receivable")
+
+const DefaultValidate = 2 * time.Second
+
+// Config describes what the kiosk plays.
+type Config struct {
+	// Primary is the station to play, in kHz.
+	Primary uint32
+	// Fallbacks are tried in order when the primary is not receivable.
+	Fallbacks []uint32
+	// Volume is set on start, 0 to 15.
+	Volume uint16
+	// MinRSSI is the signal a station needs to be played, and below which
+	// it counts as lost. Zero uses reception.DefaultMinRSSI.
+	MinRSSI uint8
+	// Validate is how long a station is listened to before deciding
+	// whether it is receivable. Zero uses DefaultValidate.
+	Validate time.Duration
+	// OnEvent, if set, is called whenever the kiosk tunes a station or
+	// monitoring fails.
+	OnEvent func(Event)
+}
+
+// Event reports that the kiosk switched to Frequency, or that no station
+// was receivable if Frequency is 0. If Err is set the device failed while
+// monitoring instead; the kiosk keeps trying.
+type Event struct {
+	Time      time.Time
+	Frequency uint32
+	Fallback  bool // a fallback rather than the primary
+	Err       error
+}
+
+// Kiosk keeps a device playing.
+type Kiosk struct {
+	cfg     Config
+	dev     *si4703.Device
+	monitor *reception.Monitor
+	ctx     context.Context
+	stop    context.CancelFunc
+}
+
+// Start tunes the first receivable station of the configuration, unmutes
+// and starts monitoring in the background.
+func Start(dev *si4703.Device, cfg Config) (*Kiosk, error) {
+	return StartContext(context.Background(), dev, cfg)
+}
+
+// StartContext is Start, with monitoring ending when ctx is done or Stop
+// is called. If ctx is done while the stations are validated it returns
+// ctx.Err().
+func StartContext(ctx context.Context, dev *si4703.Device, cfg Config) (*Kiosk, error) {
+	if cfg.MinRSSI == 0 {
+		cfg.MinRSSI = reception.DefaultMinRSSI
+	}
+	if cfg.Validate == 0 {
+		cfg.Validate = DefaultValidate
+	}
+	k := &Kiosk{
+		cfg:     cfg,
+		dev:     dev,
+		monitor: reception.New(dev),
+	}
+	k.ctx, k.stop = context.WithCancel(ctx)
+	k.monitor.MinRSSI = cfg.MinRSSI
+	k.monitor.OnEvent = func(e reception.Event) {
+		if e.Lost {
+			if _, err := k.tuneFirst(); err != nil && k.ctx.Err() == nil {
+				k.emit(Event{Time: time.Now(), Err: err})
+			}
+		}
+	}
+
+	if err := dev.SetVolume(cfg.Volume); err != nil {
+		k.stop()
+		return nil, err
+	}
+	if err := dev.DisableMute(); err != nil {
+		k.stop()
+		return nil, err
+	}
+	freq, err := k.tuneFirst()
+	if err != nil {
+		k.stop()
+		return nil, err
+	}
+	if freq == 0 {
+		err = ErrNoStation
+	}
+	go k.run()
+	return k, err
+}
+
+// Stop ends monitoring, leaving the tuner playing. It may be called more
+// than once.
+func (k *Kiosk) Stop() {
+	k.stop()
+}
+
+func (k *Kiosk) run() {
+	for {
+		select {
+		case <-k.ctx.Done():
+			return
+		case <-time.After(k.monitor.Interval):
+			if err := k.monitor.Check(time.Now()); err != nil {
+				k.emit(Event{Time: time.Now(), Err: err})
+			}
+		}
+	}
+}
+
+// tuneFirst tunes the first receivable station and returns its frequency,
+// or 0 if there is none, in which case the primary stays tuned.
+func (k *Kiosk) tuneFirst() (uint32, error) {
+	stations := append([]uint32{k.cfg.Primary}, k.cfg.Fallbacks...)
+	for i, freq := range stations {
+		ok, err := k.receivable(freq)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			k.emit(Event{Time: time.Now(), Frequency: freq, Fallback: i > 0})
+			return freq, nil
+		}
+	}
+	if err := k.dev.SetFrequency(k.cfg.Primary); err != nil {
+		return 0, err
+	}
+	k.emit(Event{Time: time.Now()})
+	return 0, nil
+}
+
+// receivable tunes freq and reports whether its average signal over
+// Validate reaches MinRSSI.
+func (k *Kiosk) receivable(freq uint32) (bool, error) {
+	if err := k.dev.SetFrequency(freq); err != nil {
+		return false, err
+	}
+	var sum, n int
+	for deadline := time.Now().Add(k.cfg.Validate); time.Now().Before(deadline); n++ {
+		status, err := k.dev.Status()
+		if err != nil {
+			return false, err
+		}
+		sum += int(status.RSSI)
+		select {
+		case <-k.ctx.Done():
+			return false, k.ctx.Err()
+		case <-time.After(k.dev.Timings().SignalSample):
+		}
+	}
+	return n > 0 && sum/n >= int(k.cfg.MinRSSI), nil
+}
+
+func (k *Kiosk) emit(e Event) {
+	if k.cfg.OnEvent != nil {
+		k.cfg.OnEvent(e)
+	}
+}
@@ -0,0 +1,112 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// Level is the severity of a log message.
+type Level uint8
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+	LevelTrace
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelError:
+		return "error"
+	case LevelWarn:
+		return "warn"
+	case LevelInfo:
+		return "info"
+	case LevelDebug:
+		return "debug"
+	default:
+		return "trace"
+	}
+}
+
+// Subsystem is the part of the driver a log message comes from.
+type Subsystem uint8
+
+const (
+	SubsystemBus  Subsystem = iota // register reads and writes
+	SubsystemTune                  // power, tuning and seeking
+	SubsystemRDS                   // RDS reception and decoding
+	numSubsystems
+)
+
+func (s Subsystem) String() string {
+	switch s {
+	case SubsystemBus:
+		return "bus"
+	case SubsystemTune:
+		return "tune"
+	default:
+		return "rds"
+	}
+}
+
+// Logger receives the driver's log messages that pass the level set for
+// their subsystem.
+type Logger interface {
+	Log(sys Subsystem, level Level, msg string)
+}
+
+// PrintLogger writes log messages with println, the default.
+type PrintLogger struct{}
+
+func (PrintLogger) Log(sys Subsystem, level Level, msg string) {
+	println(sys.String() + " " + level.String() + ": " + msg)
+}
+
+// DefaultLogLevel is the level of all subsystems of a new device.
+const DefaultLogLevel = LevelWarn
+
+// SetLogger sends log messages to l, or discards them if l is nil.
+func (d *Device) SetLogger(l Logger) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.logger = l
+}
+
+// SetLogLevel logs messages of sys up to and including level.
+func (d *Device) SetLogLevel(sys Subsystem, level Level) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if sys < numSubsystems {
+		d.logLevels[sys] = level
+	}
+}
+
+// logs reports whether a message would be logged, to skip formatting it.
+func (d *Device) logs(sys Subsystem, level Level) bool {
+	return d.logger != nil && level <= d.logLevels[sys]
+}
+
+func (d *Device) log(sys Subsystem, level Level, msg string) {
+	if d.logs(sys, level) {
+		d.logger.Log(sys, level, msg)
+	}
+}
+
+// hexBytes formats bus traffic for trace logging.
+func hexBytes(data []byte) string {
+	var rv []byte
+	for i, b := range data {
+		if i > 0 {
+			rv = append(rv, ' ')
+		}
+		rv = append(rv, "0123456789ABCDEF"[b>>4], "0123456789ABCDEF"[b&0xF])
+	}
+	return string(rv)
+}
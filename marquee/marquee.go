@@ -0,0 +1,83 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package marquee turns a RadioText of up to 64 characters into a window
+// of a few characters scrolling across a small display. Text that fits is
+// shown as is. Longer text pauses at its start, scrolls, and starts over
+// with a pause whenever the text changes.
+//
+//	m := marquee.New(16)
+//	for {
+//		fm.ReadRDS()
+//		lcd.Print(m.Frame(fm.RDSData().RadioText, time.Now()))
+//		time.Sleep(50 * time.Millisecond)
+//	}
+package marquee
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	DefaultStep  = 300 * time.Millisecond
+	DefaultPause = 2 * time.Second
+	DefaultGap   = "   "
+)
+
+// Marquee scrolls text through a window of Width characters.
+type Marquee struct {
+	Width int
+	// Step is how long each position is shown. With 0 every call to
+	// Frame advances one character.
+	Step time.Duration
+	// Pause is how long the start of the text is shown before it starts
+	// scrolling, after a change and on every loop.
+	Pause time.Duration
+	// Gap separates the end of the text from its start when it loops.
+	Gap string
+
+	text  string
+	pos   int
+	moved time.Time
+}
+
+func New(width int) *Marquee {
+	return &Marquee{
+		Width: width,
+		Step:  DefaultStep,
+		Pause: DefaultPause,
+		Gap:   DefaultGap,
+	}
+}
+
+// Frame returns the window to show at time now for text, padded with
+// spaces to Width.
+func (m *Marquee) Frame(text string, now time.Time) string {
+	if text != m.text {
+		m.text = text
+		m.pos = 0
+		m.moved = now
+	}
+	if len(text) <= m.Width {
+		return text + strings.Repeat(" ", m.Width-len(text))
+	}
+
+	wait := m.Step
+	if m.pos == 0 {
+		wait = m.Pause
+	}
+	if m.Step == 0 && m.pos != 0 || now.Sub(m.moved) >= wait {
+		m.pos = (m.pos + 1) % (len(text) + len(m.Gap))
+		m.moved = now
+	}
+
+	loop := text + m.Gap + text
+	return loop[m.pos : m.pos+m.Width]
+}
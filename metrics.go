@@ -0,0 +1,50 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// Metrics receives the driver's internal counters and gauges. See the
+// metrics package for implementations.
+type Metrics interface {
+	Count(name string, delta int64)
+	Gauge(name string, value float64)
+}
+
+// metric names reported by the driver
+const (
+	MetricReads      = "register_reads"
+	MetricWrites     = "register_writes"
+	MetricBusErrors  = "bus_errors"
+	MetricTunes      = "tunes"
+	MetricSeeks      = "seeks"
+	MetricRDSGroups  = "rds_groups"
+	MetricRDSDropped = "rds_groups_dropped"
+	MetricAFCRailed  = "afc_railed"
+	MetricRSSI       = "rssi"
+)
+
+// SetMetrics reports the driver's metrics to m, or stops reporting if m is
+// nil.
+func (d *Device) SetMetrics(m Metrics) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.metrics = m
+}
+
+func (d *Device) count(name string) {
+	if d.metrics != nil {
+		d.metrics.Count(name, 1)
+	}
+}
+
+func (d *Device) gauge(name string, value float64) {
+	if d.metrics != nil {
+		d.metrics.Gauge(name, value)
+	}
+}
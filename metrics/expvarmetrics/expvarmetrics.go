@@ -0,0 +1,41 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package expvarmetrics publishes the driver's metrics with expvar, so they
+// appear under /debug/vars of the default HTTP server.
+//
+//	fm.SetMetrics(expvarmetrics.New("si4703"))
+package expvarmetrics
+
+import "expvar"
+
+// Metrics is an si4703.Metrics backed by an expvar.Map.
+type Metrics struct {
+	vars *expvar.Map
+}
+
+// New publishes a map of the driver's metrics under name.
+func New(name string) *Metrics {
+	return &Metrics{
+		vars: expvar.NewMap(name),
+	}
+}
+
+func (m *Metrics) Count(name string, delta int64) {
+	m.vars.Add(name, delta)
+}
+
+func (m *Metrics) Gauge(name string, value float64) {
+	f, ok := m.vars.Get(name).(*expvar.Float)
+	if !ok {
+		f = new(expvar.Float)
+		m.vars.Set(name, f)
+	}
+	f.Set(value)
+}
@@ -0,0 +1,82 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package metrics provides a lightweight si4703.Metrics implementation that
+// keeps values in memory and prints them, for microcontrollers. The
+// expvarmetrics and prommetrics subpackages export the same values on hosts.
+//
+//	m := metrics.New()
+//	fm.SetMetrics(m)
+//	...
+//	m.WriteTo(machine.Serial)
+package metrics
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Memory accumulates counters and keeps the latest gauge values.
+type Memory struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	gauges   map[string]float64
+}
+
+func New() *Memory {
+	return &Memory{
+		counters: make(map[string]int64),
+		gauges:   make(map[string]float64),
+	}
+}
+
+func (m *Memory) Count(name string, delta int64) {
+	m.mu.Lock()
+	m.counters[name] += delta
+	m.mu.Unlock()
+}
+
+func (m *Memory) Gauge(name string, value float64) {
+	m.mu.Lock()
+	m.gauges[name] = value
+	m.mu.Unlock()
+}
+
+// Counter returns the current value of a counter.
+func (m *Memory) Counter(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[name]
+}
+
+// WriteTo prints every metric as a "name value" line, sorted by name.
+func (m *Memory) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	lines := make([]string, 0, len(m.counters)+len(m.gauges))
+	for name, v := range m.counters {
+		lines = append(lines, name+" "+strconv.FormatInt(v, 10))
+	}
+	for name, v := range m.gauges {
+		lines = append(lines, name+" "+strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	m.mu.Unlock()
+	sort.Strings(lines)
+
+	var total int64
+	for _, line := range lines {
+		n, err := io.WriteString(w, line+"\r\n")
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,72 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package prommetrics exports the driver's metrics to Prometheus. Counters
+// are named si4703_<name>_total and gauges si4703_<name>; they are created
+// and registered the first time the driver reports them.
+//
+//	fm.SetMetrics(prommetrics.New(prometheus.DefaultRegisterer))
+//	http.Handle("/metrics", promhttp.Handler())
+package prommetrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "si4703"
+
+// Metrics is an si4703.Metrics backed by Prometheus collectors.
+type Metrics struct {
+	reg      prometheus.Registerer
+	mu       sync.Mutex
+	counters map[string]prometheus.Counter
+	gauges   map[string]prometheus.Gauge
+}
+
+func New(reg prometheus.Registerer) *Metrics {
+	return &Metrics{
+		reg:      reg,
+		counters: make(map[string]prometheus.Counter),
+		gauges:   make(map[string]prometheus.Gauge),
+	}
+}
+
+func (m *Metrics) Count(name string, delta int64) {
+	m.mu.Lock()
+	c, ok := m.counters[name]
+	if !ok {
+		c = prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      name + "_total",
+			Help:      "Si4703 " + name + " count.",
+		})
+		m.reg.MustRegister(c)
+		m.counters[name] = c
+	}
+	m.mu.Unlock()
+	c.Add(float64(delta))
+}
+
+func (m *Metrics) Gauge(name string, value float64) {
+	m.mu.Lock()
+	g, ok := m.gauges[name]
+	if !ok {
+		g = prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      name,
+			Help:      "Si4703 " + name + ".",
+		})
+		m.reg.MustRegister(g)
+		m.gauges[name] = g
+	}
+	m.mu.Unlock()
+	g.Set(value)
+}
@@ -0,0 +1,208 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package mqtt bridges an Si4703 to an MQTT broker: the tuner state is
+// published under a base topic and commands are accepted on "<topic>/set"
+// subtopics. Optionally the bridge announces itself to Home Assistant using
+// MQTT discovery so the radio shows up on dashboards without configuration.
+//
+// The package does not depend on a particular MQTT library; wrap the
+// client of your choice (paho on hosts, the tinygo mqtt driver on
+// microcontrollers) in the small Client interface.
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// Client is the subset of an MQTT client the bridge needs.
+type Client interface {
+	Publish(topic string, retained bool, payload []byte) error
+	Subscribe(topic string, handler func(topic string, payload []byte)) error
+}
+
+type message struct {
+	topic   string
+	payload string
+}
+
+// Bridge publishes tuner state and executes commands received over MQTT.
+type Bridge struct {
+	// Topic is the base topic, "si4703/<id>" by default.
+	Topic string
+	// Name is the human readable device name used in discovery.
+	Name string
+	// Presets are the frequencies in kHz offered by the preset select.
+	Presets []uint32
+	// DiscoveryPrefix enables Home Assistant discovery when not empty.
+	DiscoveryPrefix string
+	// OnError, if set, is called with commands that failed without a
+	// device error, such as a seek finding no station.
+	OnError func(error)
+
+	id       string
+	dev      *si4703.Device
+	client   Client
+	commands chan message
+}
+
+func New(dev *si4703.Device, client Client, id string) *Bridge {
+	return &Bridge{
+		Topic:    "si4703/" + id,
+		Name:     "FM Radio",
+		id:       id,
+		dev:      dev,
+		client:   client,
+		commands: make(chan message, 8),
+	}
+}
+
+// Run subscribes to the command topics, publishes discovery information if
+// enabled and then publishes the tuner state every interval. Commands are
+// queued by the client's handlers and executed here so the device is only
+// ever used from one goroutine.
+func (b *Bridge) Run(interval time.Duration) error {
+	return b.RunContext(context.Background(), interval)
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (b *Bridge) RunContext(ctx context.Context, interval time.Duration) error {
+	for _, cmd := range []string{"frequency", "seek", "volume", "mute", "preset"} {
+		err := b.client.Subscribe(b.Topic+"/"+cmd+"/set", b.enqueue)
+		if err != nil {
+			return err
+		}
+	}
+	if b.DiscoveryPrefix != "" {
+		if err := b.PublishDiscovery(); err != nil {
+			return err
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := b.PublishState(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m := <-b.commands:
+			var re *si4703.RegisterError
+			if err := b.execute(m); errors.As(err, &re) {
+				return err
+			} else if err != nil && b.OnError != nil {
+				b.OnError(err)
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *Bridge) enqueue(topic string, payload []byte) {
+	select {
+	case b.commands <- message{topic: topic, payload: string(payload)}:
+	default:
+		// drop commands while a slow seek is still running
+	}
+}
+
+// execute carries out a command. Malformed payloads are ignored.
+func (b *Bridge) execute(m message) error {
+	cmd := strings.TrimSuffix(strings.TrimPrefix(m.topic, b.Topic+"/"), "/set")
+	payload := strings.TrimSpace(m.payload)
+	switch cmd {
+	case "frequency":
+		if khz, ok := parseMHz(payload); ok {
+			return b.dev.SetFrequency(khz)
+		}
+	case "preset":
+		if khz, ok := parseMHz(strings.TrimSuffix(payload, " MHz")); ok {
+			return b.dev.SetFrequency(khz)
+		}
+	case "seek":
+		dir := byte(1)
+		if payload == "down" {
+			dir = 0
+		}
+		_, err := b.dev.Seek(dir)
+		return err
+	case "volume":
+		if v, err := strconv.ParseUint(payload, 10, 16); err == nil {
+			return b.dev.SetVolume(uint16(v))
+		}
+	case "mute":
+		if payload == "ON" {
+			return b.dev.EnableMute()
+		}
+		return b.dev.DisableMute()
+	}
+	return nil
+}
+
+// PublishState publishes the current tuner state as retained messages.
+func (b *Bridge) PublishState() error {
+	status, err := b.dev.Status()
+	if err != nil {
+		return err
+	}
+	volume, err := b.dev.Volume()
+	if err != nil {
+		return err
+	}
+	muted, err := b.dev.Muted()
+	if err != nil {
+		return err
+	}
+	data := b.dev.RDSData()
+	mute := "OFF"
+	if muted {
+		mute = "ON"
+	}
+	stereo := "OFF"
+	if status.Stereo {
+		stereo = "ON"
+	}
+	state := []struct{ topic, value string }{
+		{"frequency", b.mhz(status.Frequency)},
+		{"preset", b.mhz(status.Frequency) + " MHz"},
+		{"rssi", strconv.Itoa(int(status.RSSI))},
+		{"stereo", stereo},
+		{"volume", strconv.Itoa(int(volume))},
+		{"mute", mute},
+		{"ps", data.ProgramService},
+		{"radiotext", data.RadioText},
+	}
+	for _, s := range state {
+		if err := b.client.Publish(b.Topic+"/"+s.topic, true, []byte(s.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mhz formats a frequency in kHz as MHz with the decimals the channel
+// grid needs.
+func (b *Bridge) mhz(khz uint32) string {
+	return b.dev.Spacing().FormatMHz(khz)
+}
+
+func parseMHz(s string) (uint32, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(f*1000 + 0.5), true
+}
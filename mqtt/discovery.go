@@ -0,0 +1,100 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package mqtt
+
+import "encoding/json"
+
+// haDevice groups all entities of the radio under one Home Assistant device.
+type haDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Model        string   `json:"model"`
+	Manufacturer string   `json:"manufacturer"`
+}
+
+// haEntity is the discovery payload shared by all entity types; unused
+// fields are omitted.
+type haEntity struct {
+	Name              string   `json:"name"`
+	UniqueID          string   `json:"unique_id"`
+	StateTopic        string   `json:"state_topic"`
+	CommandTopic      string   `json:"command_topic,omitempty"`
+	Icon              string   `json:"icon,omitempty"`
+	UnitOfMeasurement string   `json:"unit_of_measurement,omitempty"`
+	Min               *float64 `json:"min,omitempty"`
+	Max               *float64 `json:"max,omitempty"`
+	Step              *float64 `json:"step,omitempty"`
+	Mode              string   `json:"mode,omitempty"`
+	Options           []string `json:"options,omitempty"`
+	Device            haDevice `json:"device"`
+}
+
+// PublishDiscovery announces the radio to Home Assistant as a device with
+// volume, frequency, mute and preset controls plus sensors for the
+// signal and the RDS station name and text.
+func (b *Bridge) PublishDiscovery() error {
+	device := haDevice{
+		Identifiers:  []string{"si4703_" + b.id},
+		Name:         b.Name,
+		Model:        "Si4703",
+		Manufacturer: "Silicon Labs",
+	}
+	presets := make([]string, len(b.Presets))
+	for i, khz := range b.Presets {
+		presets[i] = b.mhz(khz) + " MHz"
+	}
+	band, spacing := b.dev.Band(), b.dev.Spacing()
+
+	entities := []struct {
+		component string
+		object    string
+		entity    haEntity
+	}{
+		{"number", "volume", haEntity{
+			Name: "Volume", Icon: "mdi:volume-high",
+			Min: num(0), Max: num(15), Step: num(1), Mode: "slider",
+		}},
+		{"number", "frequency", haEntity{
+			Name: "Frequency", Icon: "mdi:radio", UnitOfMeasurement: "MHz",
+			Min: num(float64(band.Bottom()) / 1000), Max: num(float64(band.Top()) / 1000),
+			Step: num(float64(spacing.KHz()) / 1000), Mode: "box",
+		}},
+		{"switch", "mute", haEntity{Name: "Mute", Icon: "mdi:volume-off"}},
+		{"select", "preset", haEntity{Name: "Preset", Icon: "mdi:playlist-music", Options: presets}},
+		{"sensor", "rssi", haEntity{Name: "Signal", Icon: "mdi:signal", UnitOfMeasurement: "dBµV"}},
+		{"sensor", "ps", haEntity{Name: "Station", Icon: "mdi:radio-tower"}},
+		{"sensor", "radiotext", haEntity{Name: "Radio Text", Icon: "mdi:text"}},
+		{"binary_sensor", "stereo", haEntity{Name: "Stereo", Icon: "mdi:surround-sound"}},
+	}
+	for _, e := range entities {
+		if e.component == "select" && len(presets) == 0 {
+			continue
+		}
+		e.entity.UniqueID = "si4703_" + b.id + "_" + e.object
+		e.entity.StateTopic = b.Topic + "/" + e.object
+		if e.component == "number" || e.component == "switch" || e.component == "select" {
+			e.entity.CommandTopic = b.Topic + "/" + e.object + "/set"
+		}
+		e.entity.Device = device
+		payload, err := json.Marshal(e.entity)
+		if err != nil {
+			return err
+		}
+		topic := b.DiscoveryPrefix + "/" + e.component + "/si4703_" + b.id + "/" + e.object + "/config"
+		if err = b.client.Publish(topic, true, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func num(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,166 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package multi coordinates several Si4703 tuners, for example one per room
+// of a multi-zone distribution box, or one that scans while another plays.
+// Every tuner is a named zone; the Si4703 has a fixed I2C address so each
+// one needs its own bus or a channel of an I2C multiplexer.
+//
+//	c := multi.New()
+//	c.Add("kitchen", &kitchen)
+//	c.Add("lounge", &lounge)
+//	c.Tune("kitchen", 90900)
+//	for _, z := range c.Status() { ... }
+package multi
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/mcilley/go-si4703"
+)
+
+var ErrUnknownZone = errors.New("multi: unknown zone")
+
+type zone struct {
+	name string
+	mu   sync.Mutex
+	dev  *si4703.Device
+}
+
+// ZoneStatus is the state of one zone.
+type ZoneStatus struct {
+	Name   string
+	Status si4703.Status
+	RDS    si4703.RDSData
+	Volume uint16
+	Muted  bool
+	// Err is set if the zone's device could not be read, in which case
+	// the other fields are incomplete.
+	Err error
+}
+
+// Coordinator owns a set of zones. Its methods are safe to call from
+// several goroutines; operations on one zone are serialized while different
+// zones can be used concurrently.
+type Coordinator struct {
+	mu    sync.RWMutex
+	zones []*zone
+}
+
+func New() *Coordinator {
+	return &Coordinator{}
+}
+
+// Add registers a configured device under name.
+func (c *Coordinator) Add(name string, dev *si4703.Device) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.zones = append(c.zones, &zone{name: name, dev: dev})
+}
+
+// Zones returns the zone names in the order they were added.
+func (c *Coordinator) Zones() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rv := make([]string, len(c.zones))
+	for i, z := range c.zones {
+		rv[i] = z.name
+	}
+	return rv
+}
+
+// Do runs f with exclusive use of the named zone's device and returns its
+// error.
+func (c *Coordinator) Do(name string, f func(dev *si4703.Device) error) error {
+	c.mu.RLock()
+	var found *zone
+	for _, z := range c.zones {
+		if z.name == name {
+			found = z
+			break
+		}
+	}
+	c.mu.RUnlock()
+	if found == nil {
+		return ErrUnknownZone
+	}
+	found.mu.Lock()
+	defer found.mu.Unlock()
+	return f(found.dev)
+}
+
+// Tune tunes the named zone to a frequency in kHz.
+func (c *Coordinator) Tune(name string, khz uint32) error {
+	return c.Do(name, func(dev *si4703.Device) error {
+		return dev.SetFrequency(khz)
+	})
+}
+
+// Seek seeks the named zone up or down to the next station.
+func (c *Coordinator) Seek(name string, up bool) error {
+	return c.Do(name, func(dev *si4703.Device) error {
+		dir := byte(0)
+		if up {
+			dir = 1
+		}
+		_, err := dev.Seek(dir)
+		return err
+	})
+}
+
+// SetVolume sets the volume of the named zone.
+func (c *Coordinator) SetVolume(name string, volume uint16) error {
+	return c.Do(name, func(dev *si4703.Device) error {
+		return dev.SetVolume(volume)
+	})
+}
+
+// SetMute mutes or unmutes the named zone.
+func (c *Coordinator) SetMute(name string, muted bool) error {
+	return c.Do(name, func(dev *si4703.Device) error {
+		if muted {
+			return dev.EnableMute()
+		}
+		return dev.DisableMute()
+	})
+}
+
+// TuneAll tunes every zone to the same frequency. Zones that fail do not
+// stop the others; the first error is returned.
+func (c *Coordinator) TuneAll(khz uint32) error {
+	var first error
+	for _, name := range c.Zones() {
+		if err := c.Tune(name, khz); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Status returns the state of every zone.
+func (c *Coordinator) Status() []ZoneStatus {
+	names := c.Zones()
+	rv := make([]ZoneStatus, 0, len(names))
+	for _, name := range names {
+		c.Do(name, func(dev *si4703.Device) error {
+			zs := ZoneStatus{Name: name, RDS: dev.RDSData()}
+			zs.Status, zs.Err = dev.Status()
+			if zs.Err == nil {
+				zs.Volume, zs.Err = dev.Volume()
+			}
+			if zs.Err == nil {
+				zs.Muted, zs.Err = dev.Muted()
+			}
+			rv = append(rv, zs)
+			return nil
+		})
+	}
+	return rv
+}
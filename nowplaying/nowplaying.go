@@ -0,0 +1,165 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package nowplaying keeps a timestamped history of what the tuned stations
+// announced as playing, so "what was that song ten minutes ago?" has an
+// answer. Entries come from the RadioText Plus artist and title when the
+// station sends them, and from the plain RadioText otherwise. The history
+// is a ring of the most recent entries across all stations.
+//
+//	h := nowplaying.New(&fm, 100)
+//	go h.Run()
+//	...
+//	for _, e := range h.Station(0xC201) { println(e.Artist, e.Title) }
+package nowplaying
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+const DefaultSize = 100
+
+// Entry is one now playing change.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Frequency uint32    `json:"frequency"` // kHz
+	PI        uint16    `json:"pi"`
+	PS        string    `json:"ps,omitempty"`
+	Artist    string    `json:"artist,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	// Text is the RadioText at the time, set when the station does not
+	// tag artist and title.
+	Text string `json:"text,omitempty"`
+}
+
+// History records now playing changes of one device. Its methods may be
+// called from several goroutines.
+type History struct {
+	mu      sync.Mutex
+	dev     *si4703.Device
+	entries []Entry
+	next    int
+	full    bool
+	last    Entry
+}
+
+// New returns a history keeping the last size entries.
+func New(dev *si4703.Device, size int) *History {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &History{
+		dev:     dev,
+		entries: make([]Entry, size),
+	}
+}
+
+// Run reads RDS and records changes after every group until the device
+// fails.
+func (h *History) Run() error {
+	return h.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (h *History) RunContext(ctx context.Context) error {
+	for {
+		_, ok, err := h.dev.ReadRDS()
+		if err != nil {
+			return err
+		}
+		if ok {
+			status, err := h.dev.Status()
+			if err != nil {
+				return err
+			}
+			h.Observe(time.Now(), status, h.dev.RDSData())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(h.dev.Timings().RDSPoll):
+		}
+	}
+}
+
+// Observe records the RDS of the tuned station at time now if what is
+// playing changed. Use it instead of Run when the application reads RDS
+// itself.
+func (h *History) Observe(now time.Time, status si4703.Status, data si4703.RDSData) {
+	if data.PI == 0 {
+		return
+	}
+	e := Entry{
+		Time:      now,
+		Frequency: status.Frequency,
+		PI:        data.PI,
+		PS:        data.ProgramService,
+		Artist:    data.Artist,
+		Title:     data.Title,
+	}
+	if e.Artist == "" && e.Title == "" {
+		if data.RadioText == "" {
+			return
+		}
+		e.Text = data.RadioText
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if e.PI == h.last.PI && e.Artist == h.last.Artist && e.Title == h.last.Title && e.Text == h.last.Text {
+		return
+	}
+	h.last = e
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Entries returns the history, oldest first.
+func (h *History) Entries() []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var rv []Entry
+	if h.full {
+		rv = append(rv, h.entries[h.next:]...)
+	}
+	return append(rv, h.entries[:h.next]...)
+}
+
+// Station returns the history of the station with the given PI code,
+// oldest first.
+func (h *History) Station(pi uint16) []Entry {
+	var rv []Entry
+	for _, e := range h.Entries() {
+		if e.PI == pi {
+			rv = append(rv, e)
+		}
+	}
+	return rv
+}
+
+// At returns the entry that was playing on the station with the given PI
+// code at time t.
+func (h *History) At(pi uint16, t time.Time) (Entry, bool) {
+	var rv Entry
+	var ok bool
+	for _, e := range h.Station(pi) {
+		if e.Time.After(t) {
+			break
+		}
+		rv, ok = e, true
+	}
+	return rv, ok
+}
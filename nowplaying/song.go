@@ -0,0 +1,138 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package nowplaying
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// DefaultSettle is how long a song must stay announced before it is
+// reported.
+const DefaultSettle = 5 * time.Second
+
+// Detector reports genuine track changes. Stations with RadioText Plus are
+// followed by their item toggle and artist/title tags. For other stations
+// the RadioText is only taken as a song when it reads "Artist - Title" or
+// "Title by Artist", which skips most station slogans and adverts.
+// Either way a song must stay announced for Settle before it is reported,
+// so scrolling text and half received tags don't fire.
+//
+//	d := nowplaying.NewDetector(&fm)
+//	d.OnSongChange = func(artist, title string) { ... }
+//	d.Run()
+type Detector struct {
+	// Settle is how long a song must stay announced before it is
+	// reported.
+	Settle time.Duration
+	// OnSongChange is called once for every new song.
+	OnSongChange func(artist, title string)
+
+	dev *si4703.Device
+	pi  uint16
+	// candidate song and when it was first seen
+	artist, title string
+	since         time.Time
+	// last reported song
+	reportedArtist, reportedTitle string
+	toggle                        bool
+}
+
+func NewDetector(dev *si4703.Device) *Detector {
+	return &Detector{
+		Settle: DefaultSettle,
+		dev:    dev,
+	}
+}
+
+// Run reads RDS and checks for song changes after every group until the
+// device fails.
+func (d *Detector) Run() error {
+	return d.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (d *Detector) RunContext(ctx context.Context) error {
+	for {
+		if _, _, err := d.dev.ReadRDS(); err != nil {
+			return err
+		}
+		d.Observe(time.Now(), d.dev.RDSData())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.dev.Timings().RDSPoll):
+		}
+	}
+}
+
+// Observe checks the decoded RDS at time now for a song change. Use it
+// instead of Run when the application reads RDS itself.
+func (d *Detector) Observe(now time.Time, data si4703.RDSData) {
+	if data.PI != d.pi {
+		// new station, whatever it plays is a change
+		d.pi = data.PI
+		d.reportedArtist, d.reportedTitle = "", ""
+		d.artist, d.title = "", ""
+	}
+
+	artist, title := data.Artist, data.Title
+	if artist == "" && title == "" {
+		var ok bool
+		if artist, title, ok = splitSong(data.RadioText); !ok {
+			return
+		}
+	} else {
+		if data.ItemToggle != d.toggle {
+			// the station says a new item started, even if the tags
+			// repeat
+			d.toggle = data.ItemToggle
+			d.reportedArtist, d.reportedTitle = "", ""
+		}
+		if !data.ItemRunning || artist == "" || title == "" {
+			return
+		}
+	}
+
+	if artist != d.artist || title != d.title {
+		d.artist, d.title, d.since = artist, title, now
+		return
+	}
+	if now.Sub(d.since) < d.Settle {
+		return
+	}
+	if artist == d.reportedArtist && title == d.reportedTitle {
+		return
+	}
+	d.reportedArtist, d.reportedTitle = artist, title
+	if d.OnSongChange != nil {
+		d.OnSongChange(artist, title)
+	}
+}
+
+// splitSong guesses artist and title from a plain RadioText.
+func splitSong(text string) (artist, title string, ok bool) {
+	for _, sep := range []string{" - ", " / "} {
+		if i := strings.Index(text, sep); i > 0 {
+			artist = strings.TrimSpace(text[:i])
+			title = strings.TrimSpace(text[i+len(sep):])
+			return artist, title, artist != "" && title != ""
+		}
+	}
+	if i := strings.LastIndex(text, " by "); i > 0 {
+		title = strings.TrimSpace(text[:i])
+		artist = strings.TrimSpace(text[i+len(" by "):])
+		return artist, title, artist != "" && title != ""
+	}
+	return "", "", false
+}
@@ -0,0 +1,18 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// Pin is a GPIO output. On tinygo targets machine.Pin satisfies it and is
+// configured as an output automatically; on hosts wrap the GPIO library of
+// your choice and configure the line yourself.
+type Pin interface {
+	High()
+	Low()
+}
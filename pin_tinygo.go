@@ -0,0 +1,63 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+//go:build tinygo
+
+package si4703
+
+import "machine"
+
+func defaultResetPin() Pin {
+	return machine.Pin(machine.GPIO15)
+}
+
+func configureOutput(p Pin) {
+	if pin, ok := p.(machine.Pin); ok {
+		pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	}
+}
+
+// ListenInterrupts calls Interrupt whenever GPIO2 of the chip, connected
+// to pin, pulses low.
+func (d *Device) ListenInterrupts(pin machine.Pin) error {
+	pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return pin.SetInterrupt(machine.PinFalling, func(machine.Pin) {
+		d.Interrupt()
+	})
+}
+
+// UseInterruptPin routes the chosen interrupts to GPIO2 and listens for
+// them on pin, so tunes and seeks wait for the STC interrupt instead of
+// reading the status continuously. See SetInterrupts.
+func (d *Device) UseInterruptPin(pin machine.Pin, stc, rds bool) error {
+	if err := d.ListenInterrupts(pin); err != nil {
+		return err
+	}
+	return d.SetInterrupts(stc, rds)
+}
+
+// AbortOnPin aborts a seek or scan in progress whenever pin sees change,
+// such as the push of a tuning knob's switch. It takes over the pin's
+// interrupt handler; applications that need the handler themselves can
+// send to a channel passed to SetAbortInput instead.
+func (d *Device) AbortOnPin(pin machine.Pin, change machine.PinChange) error {
+	input := make(chan struct{}, 1)
+	pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	err := pin.SetInterrupt(change, func(machine.Pin) {
+		select {
+		case input <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return err
+	}
+	d.SetAbortInput(input)
+	return nil
+}
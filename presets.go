@@ -0,0 +1,98 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// PresetsKey is the key presets are stored under.
+const PresetsKey = "presets"
+
+var (
+	ErrInvalidPreset = errors.New("si4703: invalid preset slot")
+	ErrEmptyPreset   = errors.New("si4703: preset slot empty")
+)
+
+// Presets is a fixed number of station memories, numbered from 0, for the
+// preset buttons of a radio front end.
+type Presets struct {
+	dev   *Device
+	slots []uint32
+}
+
+// NewPresets returns n empty presets for dev.
+func NewPresets(dev *Device, n int) *Presets {
+	return &Presets{
+		dev:   dev,
+		slots: make([]uint32, n),
+	}
+}
+
+// Store saves the frequency khz into slot. Zero empties the slot.
+func (p *Presets) Store(slot int, khz uint32) error {
+	if slot < 0 || slot >= len(p.slots) {
+		return ErrInvalidPreset
+	}
+	p.slots[slot] = khz
+	return nil
+}
+
+// StoreCurrent saves the tuned frequency into slot.
+func (p *Presets) StoreCurrent(slot int) error {
+	khz, err := p.dev.Frequency()
+	if err != nil {
+		return err
+	}
+	return p.Store(slot, khz)
+}
+
+// Recall tunes to the frequency stored in slot.
+func (p *Presets) Recall(slot int) error {
+	if slot < 0 || slot >= len(p.slots) {
+		return ErrInvalidPreset
+	}
+	khz := p.slots[slot]
+	if khz == 0 {
+		return ErrEmptyPreset
+	}
+	return p.dev.SetFrequency(khz)
+}
+
+// List returns the frequencies in kHz stored in each slot, 0 for empty
+// ones.
+func (p *Presets) List() []uint32 {
+	return append([]uint32(nil), p.slots...)
+}
+
+// Save writes the presets to storage.
+func (p *Presets) Save(storage Storage) error {
+	data := make([]byte, 0, 4*len(p.slots))
+	for _, khz := range p.slots {
+		data = binary.BigEndian.AppendUint32(data, khz)
+	}
+	return storage.Store(PresetsKey, data)
+}
+
+// Load reads presets saved by Save, if any. Slots beyond those saved are
+// left as they are, saved slots beyond the number of presets are dropped.
+func (p *Presets) Load(storage Storage) error {
+	data, err := storage.Load(PresetsKey)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for i := 0; i < len(p.slots) && 4*i+4 <= len(data); i++ {
+		p.slots[i] = binary.BigEndian.Uint32(data[4*i:])
+	}
+	return nil
+}
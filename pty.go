@@ -0,0 +1,70 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// RDSStandard selects how the RDS fields whose meaning differs between the
+// European RDS standard and the North American RBDS standard are decoded.
+type RDSStandard uint8
+
+const (
+	StandardRDS  RDSStandard = iota // EN 50067, used outside North America
+	StandardRBDS                    // NRSC-4, used in the USA and Canada
+)
+
+// programTypes holds the programme type names of each standard. RBDS
+// leaves 24 to 28 unassigned.
+var programTypes = [...][32]string{
+	StandardRDS: {
+		"None", "News", "Current Affairs", "Information",
+		"Sport", "Education", "Drama", "Culture",
+		"Science", "Varied", "Pop Music", "Rock Music",
+		"Easy Listening", "Light Classical", "Serious Classical", "Other Music",
+		"Weather", "Finance", "Children's Programmes", "Social Affairs",
+		"Religion", "Phone-in", "Travel", "Leisure",
+		"Jazz Music", "Country Music", "National Music", "Oldies Music",
+		"Folk Music", "Documentary", "Alarm Test", "Alarm",
+	},
+	StandardRBDS: {
+		"None", "News", "Information", "Sports",
+		"Talk", "Rock", "Classic Rock", "Adult Hits",
+		"Soft Rock", "Top 40", "Country", "Oldies",
+		"Soft", "Nostalgia", "Jazz", "Classical",
+		"Rhythm and Blues", "Soft Rhythm and Blues", "Language", "Religious Music",
+		"Religious Talk", "Personality", "Public", "College",
+		"", "", "", "",
+		"", "Weather", "Emergency Test", "Emergency",
+	},
+}
+
+// ProgramTypeName returns the name of programme type pty in the standard,
+// or "" for an unassigned or out of range code.
+func (s RDSStandard) ProgramTypeName(pty uint8) string {
+	if int(s) >= len(programTypes) || pty >= 32 {
+		return ""
+	}
+	return programTypes[s][pty]
+}
+
+// SetRDSStandard selects the standard RDS data is interpreted with.
+// The default is StandardRDS; receivers in North America should select
+// StandardRBDS.
+func (d *Device) SetRDSStandard(s RDSStandard) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.standard = s
+}
+
+// ProgramTypeName returns the name of the tuned station's programme type
+// in the selected standard, see SetRDSStandard.
+func (d *Device) ProgramTypeName() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.standard.ProgramTypeName(d.decoder.pty)
+}
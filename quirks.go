@@ -0,0 +1,121 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// quirk is a workaround needed by some revisions or firmware versions of
+// the chip. Configure checks the table once the chip is powered up and the
+// rest of the driver looks at the flags instead of at the chip revision.
+type quirk struct {
+	name  string
+	match func(info DeviceInfo) bool
+	flags uint16
+}
+
+const (
+	// SYSCONFIG3 seek SNR and impulse count are only implemented from
+	// Rev C on, and must be left 0 before that.
+	quirkNoSeekQuality uint16 = 1 << iota
+	// VOLEXT is only implemented from Rev C on.
+	quirkNoVolumeExt
+	// Early firmware passes on groups with uncorrectable blocks in standard
+	// RDS mode. Verbose mode is used instead and such groups are dropped
+	// by looking at the block error counts.
+	quirkRDSVerbose
+	// Early firmware occasionally presents the blocks of a group shifted
+	// by one, which shows as block A not carrying the station's PI code.
+	quirkRDSBlockOrder
+)
+
+// first firmware without the RDS problems, that of the Si4702/03-C19
+const firmwareRDSFixed = 19
+
+var quirks = []quirk{
+	{
+		name:  "pre-rev-c-seek",
+		match: func(info DeviceInfo) bool { return info.Revision < revC },
+		flags: quirkNoSeekQuality,
+	},
+	{
+		name:  "pre-rev-c-volext",
+		match: func(info DeviceInfo) bool { return info.Revision < revC },
+		flags: quirkNoVolumeExt,
+	},
+	{
+		name:  "early-firmware-rds-verbose",
+		match: func(info DeviceInfo) bool { return info.Firmware < firmwareRDSFixed },
+		flags: quirkRDSVerbose,
+	},
+	{
+		name:  "early-firmware-rds-block-order",
+		match: func(info DeviceInfo) bool { return info.Firmware < firmwareRDSFixed },
+		flags: quirkRDSBlockOrder,
+	},
+}
+
+// applyQuirks selects the workarounds for the chip from the registers last
+// read.
+func (d *Device) applyQuirks() error {
+	info := d.deviceInfo()
+	d.quirks = 0
+	for _, q := range quirks {
+		if q.match(info) {
+			d.log(SubsystemTune, LevelInfo, "applying quirk "+q.name)
+			d.quirks |= q.flags
+		}
+	}
+	if d.quirks&quirkNoSeekQuality != 0 {
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3] & 0xFF00
+	}
+	if d.quirks&quirkNoVolumeExt != 0 {
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3] &^ (1 << VOLEXT)
+	}
+	if d.quirks&quirkRDSVerbose != 0 {
+		d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << RDSMODE)
+	}
+	return d.updateRegisters()
+}
+
+// workarounds returns the names of the quirks applied to the chip.
+func (d *Device) workarounds() []string {
+	var rv []string
+	for _, q := range quirks {
+		if d.quirks&q.flags != 0 {
+			rv = append(rv, q.name)
+		}
+	}
+	return rv
+}
+
+// dropRDS returns why a group read from the registers must be discarded
+// under the quirks applied, or "" to keep it.
+func (d *Device) dropRDS(g RDSGroup) string {
+	if d.quirks&quirkRDSVerbose != 0 {
+		status, readchan := d.registers[STATUSRSSI], d.registers[READCHAN]
+		if status>>BLERA&0x3 == blerUncorrectable ||
+			readchan>>BLERB&0x3 == blerUncorrectable ||
+			readchan>>BLERC&0x3 == blerUncorrectable ||
+			readchan>>BLERD&0x3 == blerUncorrectable {
+			return "uncorrectable block"
+		}
+	}
+	if d.quirks&quirkRDSBlockOrder != 0 && d.decoder.pi != 0 && g.A != d.decoder.pi {
+		// a new PI code seen twice in a row is believed
+		if g.A != d.decoder.piCandidate {
+			d.decoder.piCandidate = g.A
+			return "block A is not the PI code"
+		}
+	}
+	d.decoder.piCandidate = 0
+	return ""
+}
+
+func (d *Device) hasQuirk(flag uint16) bool {
+	return d.quirks&flag != 0
+}
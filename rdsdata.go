@@ -0,0 +1,238 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"strings"
+	"time"
+)
+
+// RDSData is the information decoded so far from the RDS groups of the
+// currently tuned station.
+type RDSData struct {
+	PI                  uint16
+	ProgramType         uint8
+	TrafficProgram      bool
+	TrafficAnnouncement bool
+	ProgramService      string // station name, up to 8 characters
+	RadioText           string // up to 64 characters
+	RadioTextComplete   bool   // every segment up to the end received
+	ECC                 uint8  // extended country code, 0 until received
+
+	// alternative frequencies of the programme in kHz from group 0A,
+	// excluding the tuned one, in the order received
+	AF []uint32
+	// time of the last clock time group, in the station's time zone
+	ClockTime time.Time
+
+	// slow labelling codes of group 1A, 0 until received
+	Language        uint8  // language code of the programme
+	TMCIdentifier   uint16 // 12 bit TMC identification
+	EWSChannel      uint16 // 12 bit emergency warning system channel
+	LinkageActuator bool
+
+	// now playing information tagged with RadioText Plus
+	Artist      string
+	Title       string
+	ItemToggle  bool // flips whenever a new item starts
+	ItemRunning bool
+}
+
+// rdsDecoder accumulates RDS groups into an RDSData.
+type rdsDecoder struct {
+	pi  uint16
+	pty uint8
+	tp  bool
+	ta  bool
+	ecc uint8
+	// PI code that differed from pi in the last group, see dropRDS
+	piCandidate uint16
+	ps          [8]byte
+	rt          [64]byte
+	// remaining slow labelling codes
+	lang  uint8
+	tmcID uint16
+	ews   uint16
+	la    bool
+	// text A/B flag of the radiotext currently being assembled
+	rtAB   byte
+	rtplus rtPlusDecoder
+	// radiotext segments received, and whether they came in version B
+	// groups of 2 characters instead of 4
+	rtSeen uint16
+	rtB    bool
+	// PS characters as last received, the positions received the same
+	// twice in a row, and the last name confirmed in every position
+	psLast [8]byte
+	psSame uint8
+	psName [8]byte
+	psOK   bool
+	// alternative frequencies, leaving out the tuned one in kHz
+	af    afDecoder
+	tuned uint32
+	ct    time.Time
+}
+
+func (r *rdsDecoder) update(g RDSGroup) {
+	r.pi = g.A
+	r.tp = g.B>>10&0x1 == 1
+	r.pty = uint8(g.B >> 5 & 0x1F)
+
+	groupType := g.B >> 12
+	versionB := g.B>>11&0x1 == 1
+	if code := g.B >> 11; code == 3<<1 && g.D == rtPlusAID {
+		r.rtplus.code = g.B & 0x1F
+	} else if r.rtplus.code != 0 && code == r.rtplus.code {
+		r.rtplus.update(g, r.rt[:])
+		return
+	}
+	switch groupType {
+	case 0:
+		r.ta = g.B>>4&0x1 == 1
+		seg := g.B & 0x3
+		r.ps[seg*2] = byte(g.D >> 8)
+		r.ps[seg*2+1] = byte(g.D)
+		r.confirmPS(int(seg) * 2)
+		r.confirmPS(int(seg)*2 + 1)
+		if !versionB {
+			r.af.update(g.C, r.tuned)
+		}
+	case 1:
+		if !versionB {
+			r.updateSlowLabelling(g.C)
+		}
+	case 2:
+		ab := byte(g.B >> 4 & 0x1)
+		if ab != r.rtAB {
+			// the broadcaster flipped the A/B flag, a new text follows
+			r.rt = [64]byte{}
+			r.rtAB = ab
+			r.rtSeen = 0
+		}
+		seg := g.B & 0xF
+		r.rtSeen |= 1 << seg
+		r.rtB = versionB
+		if versionB {
+			r.rt[seg*2] = byte(g.D >> 8)
+			r.rt[seg*2+1] = byte(g.D)
+		} else {
+			r.rt[seg*4] = byte(g.C >> 8)
+			r.rt[seg*4+1] = byte(g.C)
+			r.rt[seg*4+2] = byte(g.D >> 8)
+			r.rt[seg*4+3] = byte(g.D)
+		}
+	case 4:
+		if versionB {
+			break
+		}
+		if t, ok := decodeClockTime(g); ok {
+			r.ct = t
+		}
+	case 15:
+		// the fast basic tuning group 15B repeats TA
+		if versionB {
+			r.ta = g.B>>4&0x1 == 1
+		}
+	}
+}
+
+// updateSlowLabelling decodes block C of a group 1A. Its variant code
+// selects which of the slow labelling codes the low 12 bits carry; paging
+// and broadcaster specific variants are ignored.
+func (r *rdsDecoder) updateSlowLabelling(c uint16) {
+	r.la = c>>15 == 1
+	switch c >> 12 & 0x7 {
+	case 0:
+		// bits 11:8 are the radio paging codes
+		r.ecc = uint8(c)
+	case 1:
+		r.tmcID = c & 0xFFF
+	case 3:
+		r.lang = uint8(c)
+	case 7:
+		r.ews = c & 0xFFF
+	}
+}
+
+func (r *rdsDecoder) data() RDSData {
+	return RDSData{
+		PI:                  r.pi,
+		ProgramType:         r.pty,
+		TrafficProgram:      r.tp,
+		TrafficAnnouncement: r.ta,
+		ProgramService:      rdsString(r.ps[:]),
+		RadioText:           rdsString(r.rt[:]),
+		RadioTextComplete:   r.rtComplete(),
+		ECC:                 r.ecc,
+		AF:                  append([]uint32(nil), r.af.list...),
+		ClockTime:           r.ct,
+		Language:            r.lang,
+		TMCIdentifier:       r.tmcID,
+		EWSChannel:          r.ews,
+		LinkageActuator:     r.la,
+		Artist:              r.rtplus.artist,
+		Title:               r.rtplus.title,
+		ItemToggle:          r.rtplus.toggle,
+		ItemRunning:         r.rtplus.running,
+	}
+}
+
+// confirmPS checks the PS character just received at pos against the one
+// received there before. Once every position has been received the same
+// twice in a row the name is confirmed.
+func (r *rdsDecoder) confirmPS(pos int) {
+	if r.ps[pos] == r.psLast[pos] {
+		r.psSame |= 1 << pos
+	} else {
+		r.psLast[pos] = r.ps[pos]
+		r.psSame &^= 1 << pos
+	}
+	if r.psSame == 0xFF {
+		r.psName = r.psLast
+		r.psOK = true
+	}
+}
+
+// rtComplete reports whether all segments of the radiotext have been
+// received, up to the one with the carriage return ending a shorter text.
+func (r *rdsDecoder) rtComplete() bool {
+	size := 4
+	if r.rtB {
+		size = 2
+	}
+	for seg := 0; seg < 16; seg++ {
+		if r.rtSeen&(1<<seg) == 0 {
+			return false
+		}
+		for _, c := range r.rt[seg*size : seg*size+size] {
+			if c == 0x0D {
+				return true
+			}
+		}
+	}
+	return true
+}
+
+// rdsString converts received RDS characters to a string, stopping at the
+// carriage return that terminates a shorter radiotext. Characters not yet
+// received and control codes are shown as spaces.
+func rdsString(chars []byte) string {
+	var rv strings.Builder
+	for _, c := range chars {
+		if c == 0x0D {
+			break
+		}
+		if c < 0x20 || c > 0x7E {
+			c = ' '
+		}
+		rv.WriteByte(c)
+	}
+	return strings.TrimRight(rv.String(), " ")
+}
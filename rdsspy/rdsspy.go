@@ -0,0 +1,127 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+//go:build !tinygo
+
+// Package rdsspy serves the raw RDS groups received by the tuner over TCP
+// as lines of four hexadecimal blocks, the ASCII format RDS Spy and other
+// desktop analysers read from a TCP source. A single board computer with an
+// Si4703 becomes a networked RDS probe.
+//
+//	srv := rdsspy.New(&fm)
+//	log.Fatal(srv.ListenAndServe(":23000"))
+package rdsspy
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// Server sends every RDS group to all connected clients.
+type Server struct {
+	dev     *si4703.Device
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+}
+
+func New(dev *si4703.Device) *Server {
+	return &Server{
+		dev:     dev,
+		clients: make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe reads RDS in the background and accepts clients on addr.
+func (s *Server) ListenAndServe(addr string) error {
+	return s.ListenAndServeContext(context.Background(), addr)
+}
+
+// ListenAndServeContext is ListenAndServe until ctx is done, when the
+// listener is closed and ctx.Err() is returned.
+func (s *Server) ListenAndServeContext(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	go s.RunContext(ctx)
+	defer context.AfterFunc(ctx, func() { ln.Close() })()
+	err = s.Serve(ln)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// Serve accepts clients on ln. Anything clients send is ignored.
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.clients[conn] = struct{}{}
+		s.mu.Unlock()
+		go func() {
+			io.Copy(io.Discard, conn)
+			s.drop(conn)
+		}()
+	}
+}
+
+// Run reads RDS and sends each group to the clients until the device
+// fails.
+func (s *Server) Run() error {
+	return s.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (s *Server) RunContext(ctx context.Context) error {
+	for {
+		g, ok, err := s.dev.ReadRDS()
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.Send(g)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.dev.Timings().RDSPoll):
+		}
+	}
+}
+
+// Send writes one group to every client, dropping clients that fail.
+func (s *Server) Send(g si4703.RDSGroup) {
+	line := []byte(g.String() + "\r\n")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.clients {
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Write(line); err != nil {
+			conn.Close()
+			delete(s.clients, conn)
+		}
+	}
+}
+
+func (s *Server) drop(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn.Close()
+	delete(s.clients, conn)
+}
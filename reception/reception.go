@@ -0,0 +1,182 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package reception tells when the tuned station is lost and when it comes
+// back. The signal counts as lost once the RSSI stays below MinRSSI for
+// LostAfter, and as recovered once it stays at or above MinRSSI+Hysteresis
+// for RecoverAfter, so a station hovering around the threshold doesn't
+// flap between the two.
+//
+// With Reseek set, a lost station is given up on and the monitor seeks to
+// the next receivable one, which suits a radio travelling in a vehicle.
+//
+//	m := reception.New(&fm)
+//	m.MinRSSI = 20
+//	m.OnEvent = func(e reception.Event) { ... }
+//	m.Run()
+package reception
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+const (
+	DefaultMinRSSI      = 15 // dBµV
+	DefaultHysteresis   = 5  // dB
+	DefaultLostAfter    = 3 * time.Second
+	DefaultRecoverAfter = 2 * time.Second
+	DefaultInterval     = 250 * time.Millisecond
+)
+
+// Event reports that the signal was lost (Lost) or recovered, or that the
+// monitor seeked away from a lost station (Reseek), in which case From is
+// the lost station and Station the new one.
+type Event struct {
+	Lost    bool
+	Reseek  bool
+	Time    time.Time
+	Station si4703.StationInfo
+	From    si4703.StationInfo
+}
+
+// Monitor watches the signal of the tuned station on one device.
+type Monitor struct {
+	// MinRSSI is the level below which the signal is considered too weak.
+	MinRSSI uint8
+	// Hysteresis is added to MinRSSI for the level the signal must reach
+	// again before it counts as recovered.
+	Hysteresis uint8
+	// LostAfter is how long the signal must stay weak before it is lost.
+	LostAfter time.Duration
+	// RecoverAfter is how long the signal must stay good before it is
+	// recovered.
+	RecoverAfter time.Duration
+	// Interval is how often Run checks the signal.
+	Interval time.Duration
+
+	// Reseek seeks in ReseekDir, 1 for up and 0 for down, when the signal
+	// is lost.
+	Reseek    bool
+	ReseekDir byte
+	// HasAF, if set, is asked before reseeking whether an alternative
+	// frequency of the lost station is usable. If it is, the monitor
+	// leaves following the station to AF switching.
+	HasAF func(si4703.StationInfo) bool
+
+	OnEvent func(Event)
+
+	dev       *si4703.Device
+	lost      bool
+	frequency uint32
+	// start of the current run of readings on the other side of the
+	// threshold, zero when there is none
+	since time.Time
+}
+
+func New(dev *si4703.Device) *Monitor {
+	return &Monitor{
+		MinRSSI:      DefaultMinRSSI,
+		Hysteresis:   DefaultHysteresis,
+		LostAfter:    DefaultLostAfter,
+		RecoverAfter: DefaultRecoverAfter,
+		Interval:     DefaultInterval,
+		ReseekDir:    1,
+		dev:          dev,
+	}
+}
+
+// Lost reports whether the signal is currently considered lost.
+func (m *Monitor) Lost() bool {
+	return m.lost
+}
+
+// Run checks the signal every Interval until the device fails.
+func (m *Monitor) Run() error {
+	return m.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (m *Monitor) RunContext(ctx context.Context) error {
+	for {
+		if err := m.Check(time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.Interval):
+		}
+	}
+}
+
+// Check takes a reading at time now and emits an event if the signal was
+// lost or recovered. Retuning starts over with the signal assumed good.
+func (m *Monitor) Check(now time.Time) error {
+	station, err := m.dev.CurrentStation()
+	if err != nil {
+		return err
+	}
+	if station.Frequency != m.frequency {
+		m.frequency = station.Frequency
+		m.lost = false
+		m.since = time.Time{}
+	}
+
+	var changing bool
+	var after time.Duration
+	if m.lost {
+		changing = int(station.RSSI) >= int(m.MinRSSI)+int(m.Hysteresis)
+		after = m.RecoverAfter
+	} else {
+		changing = station.RSSI < m.MinRSSI
+		after = m.LostAfter
+	}
+	if !changing {
+		m.since = time.Time{}
+		return nil
+	}
+	if m.since.IsZero() {
+		m.since = now
+	}
+	if now.Sub(m.since) < after {
+		return nil
+	}
+	m.lost = !m.lost
+	m.since = time.Time{}
+	m.emit(Event{Lost: m.lost, Time: now, Station: station})
+	if m.lost && m.Reseek && (m.HasAF == nil || !m.HasAF(station)) {
+		return m.reseek(now, station)
+	}
+	return nil
+}
+
+// reseek seeks to the next station and starts watching it.
+func (m *Monitor) reseek(now time.Time, from si4703.StationInfo) error {
+	if _, err := m.dev.Seek(m.ReseekDir); err != nil {
+		return err
+	}
+	station, err := m.dev.CurrentStation()
+	if err != nil {
+		return err
+	}
+	m.frequency = station.Frequency
+	m.lost = false
+	m.since = time.Time{}
+	m.emit(Event{Reseek: true, Time: now, Station: station, From: from})
+	return nil
+}
+
+func (m *Monitor) emit(e Event) {
+	if m.OnEvent != nil {
+		m.OnEvent(e)
+	}
+}
@@ -0,0 +1,53 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "errors"
+
+// ErrInvalidRegion is returned by SetRegion for an unknown region.
+var ErrInvalidRegion = errors.New("si4703: invalid region")
+
+// Region is a set of band, channel spacing and de-emphasis matching the FM
+// broadcasting rules of part of the world.
+type Region uint8
+
+const (
+	RegionUSA       Region = iota // 87.5-108 MHz, 200 kHz, 75 µs
+	RegionEurope                  // 87.5-108 MHz, 100 kHz, 50 µs
+	RegionJapan                   // 76-108 MHz, 100 kHz, 50 µs
+	RegionAustralia               // 87.5-108 MHz, 200 kHz, 50 µs
+)
+
+var regions = [...]struct {
+	band       Band
+	spacing    Spacing
+	deemphasis Deemphasis
+}{
+	RegionUSA:       {BandUSEurope, Spacing200kHz, Deemphasis75us},
+	RegionEurope:    {BandUSEurope, Spacing100kHz, Deemphasis50us},
+	RegionJapan:     {BandJapanWide, Spacing100kHz, Deemphasis50us},
+	RegionAustralia: {BandUSEurope, Spacing200kHz, Deemphasis50us},
+}
+
+// SetRegion sets the band, channel spacing and de-emphasis for a region in
+// one write. The tuned channel number is kept, so retune afterwards.
+func (d *Device) SetRegion(r Region) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if int(r) >= len(regions) {
+		return ErrInvalidRegion
+	}
+	settings := regions[r]
+	return d.modify("region", func() {
+		d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1]&^(1<<DE) | uint16(settings.deemphasis)<<DE
+		d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2]&^(0x3<<BAND0|0x3<<SPACE0) |
+			uint16(settings.band)<<BAND0 | uint16(settings.spacing)<<SPACE0
+	})
+}
@@ -0,0 +1,74 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// RDS open data application ID of RadioText Plus
+const rtPlusAID = 0x4BD7
+
+// RT+ content types used for now playing information
+const (
+	rtPlusTitle  = 1
+	rtPlusArtist = 4
+)
+
+// rtPlusDecoder extracts tagged items from the radiotext using RadioText
+// Plus groups. The group type carrying RT+ is announced in group 3A.
+type rtPlusDecoder struct {
+	// group type code as in block B bits 15-11, 0 until announced
+	code    uint16
+	toggle  bool
+	running bool
+	artist  string
+	title   string
+}
+
+// update decodes an RT+ group. Each group carries two tags of a content
+// type, a start offset and a length into the radiotext.
+func (p *rtPlusDecoder) update(g RDSGroup, rt []byte) {
+	toggle := g.B>>4&0x1 == 1
+	if toggle != p.toggle {
+		// a new item started, forget the tags of the previous one
+		p.toggle = toggle
+		p.artist = ""
+		p.title = ""
+	}
+	p.running = g.B>>3&0x1 == 1
+
+	type1 := (g.B&0x7)<<3 | g.C>>13
+	start1 := g.C >> 7 & 0x3F
+	len1 := g.C >> 1 & 0x3F
+	type2 := (g.C&0x1)<<5 | g.D>>11
+	start2 := g.D >> 5 & 0x3F
+	len2 := g.D & 0x1F
+
+	p.tag(type1, start1, len1, rt)
+	p.tag(type2, start2, len2, rt)
+}
+
+func (p *rtPlusDecoder) tag(contentType, start, length uint16, rt []byte) {
+	// the length marker is one less than the length
+	end := int(start) + int(length) + 1
+	if contentType == 0 || end > len(rt) {
+		return
+	}
+	field := rt[start:end]
+	for _, c := range field {
+		if c == 0 {
+			// that part of the radiotext has not been received yet
+			return
+		}
+	}
+	switch contentType {
+	case rtPlusTitle:
+		p.title = rdsString(field)
+	case rtPlusArtist:
+		p.artist = rdsString(field)
+	}
+}
@@ -0,0 +1,137 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"context"
+	"time"
+)
+
+// ScanPreview is the SCAN button of a car radio. It seeks up to each
+// receivable station in turn and plays it for dwell, reading RDS meanwhile
+// so the station name can be shown, muting again before moving on. It
+// returns after StopScan, leaving the current station playing, or once the
+// whole band has been previewed, returning to the station it started from.
+//
+// The device is not held locked during the scan, so StopScan and the usual
+// status and RDS methods may be called from other goroutines. Only one
+// preview runs at a time; ScanPreview returns ErrBusy while one is.
+func (d *Device) ScanPreview(dwell time.Duration) error {
+	return d.ScanPreviewContext(context.Background(), dwell)
+}
+
+// ScanPreviewContext is ScanPreview, also stopping on the station being
+// previewed once ctx is done, when it returns ctx.Err().
+func (d *Device) ScanPreviewContext(ctx context.Context, dwell time.Duration) error {
+	d.mu.Lock()
+	if d.scanStop != nil {
+		d.mu.Unlock()
+		return ErrBusy
+	}
+	stop := make(chan struct{})
+	d.scanStop = stop
+	abort := d.abort
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.scanStop = nil
+		d.mu.Unlock()
+	}()
+
+	muted, err := d.Muted()
+	if err != nil {
+		return err
+	}
+	status, err := d.Status()
+	if err != nil {
+		return err
+	}
+	start := status.Frequency
+	first := uint32(0)
+	for {
+		if err := d.EnableMute(); err != nil {
+			return err
+		}
+		freq, err := d.SeekContext(ctx, 1)
+		if err == ErrSeekAborted || err != nil && err == ctx.Err() {
+			if !muted {
+				if err := d.DisableMute(); err != nil {
+					return err
+				}
+			}
+			return ctx.Err()
+		} else if err == ErrSeekFailed {
+			break
+		} else if err != nil {
+			return err
+		}
+		if freq == first || freq == start && first != 0 {
+			break
+		}
+		if first == 0 {
+			first = freq
+		}
+		if err := d.DisableMute(); err != nil {
+			return err
+		}
+		if stopped, err := d.scanDwell(ctx, dwell, stop, abort); stopped || err != nil {
+			if err == nil {
+				err = ctx.Err()
+			}
+			return err
+		}
+	}
+
+	// full circle without a stop, go back to where the scan started
+	d.mu.Lock()
+	err = d.setFrequency(context.Background(), start)
+	d.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !muted {
+		return d.DisableMute()
+	}
+	return nil
+}
+
+// scanDwell plays the station for dwell and reports whether the scan was
+// stopped or aborted by user input meanwhile.
+func (d *Device) scanDwell(ctx context.Context, dwell time.Duration, stop chan struct{}, abort <-chan struct{}) (bool, error) {
+	t := time.NewTimer(dwell)
+	defer t.Stop()
+	poll := d.Timings().RDSPoll
+	for {
+		if _, _, err := d.ReadRDS(); err != nil {
+			return false, err
+		}
+		select {
+		case <-stop:
+			return true, nil
+		case <-abort:
+			return true, nil
+		case <-ctx.Done():
+			return true, nil
+		case <-t.C:
+			return false, nil
+		case <-time.After(poll):
+		}
+	}
+}
+
+// StopScan ends a running ScanPreview on the station being previewed.
+func (d *Device) StopScan() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.scanStop != nil {
+		close(d.scanStop)
+		d.scanStop = nil
+	}
+}
@@ -0,0 +1,102 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "context"
+
+// Station is a station found by ScanBand.
+type Station struct {
+	Frequency uint32 // kHz
+	RSSI      uint8  // dBµV
+	Stereo    bool
+}
+
+// ScanBand seeks once around the whole band, muted, and returns the
+// stations it stopped on in the order found. It then returns to the
+// station it started from.
+func (d *Device) ScanBand() ([]Station, error) {
+	return d.ScanBandContext(context.Background())
+}
+
+// ScanBandContext is ScanBand, giving up once ctx is done, when it returns
+// the stations found so far and ctx.Err().
+func (d *Device) ScanBandContext(ctx context.Context) ([]Station, error) {
+	muted, err := d.Muted()
+	if err != nil {
+		return nil, err
+	}
+	start, err := d.Frequency()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.EnableMute(); err != nil {
+		return nil, err
+	}
+	stations, err := d.scanBand(ctx)
+
+	// go back to where the scan started
+	d.mu.Lock()
+	terr := d.setFrequency(context.Background(), start)
+	d.mu.Unlock()
+	if err == nil {
+		err = terr
+	}
+	if !muted {
+		if merr := d.DisableMute(); err == nil {
+			err = merr
+		}
+	}
+	return stations, err
+}
+
+func (d *Device) scanBand(ctx context.Context) ([]Station, error) {
+	var rv []Station
+	for {
+		freq, err := d.SeekContext(ctx, 1)
+		if err == ErrSeekFailed {
+			return rv, nil
+		} else if err != nil {
+			return rv, err
+		}
+		// back around to the first station found
+		if len(rv) > 0 && freq == rv[0].Frequency {
+			return rv, nil
+		}
+		status, err := d.Status()
+		if err != nil {
+			return rv, err
+		}
+		rv = append(rv, Station{
+			Frequency: freq,
+			RSSI:      status.RSSI,
+			Stereo:    status.Stereo,
+		})
+	}
+}
+
+// AutoTuneStrongest scans the band and tunes to the station with the
+// highest RSSI, which it returns. It fails with ErrSeekFailed when the band
+// is empty, leaving the tuner where it was.
+func (d *Device) AutoTuneStrongest() (Station, error) {
+	stations, err := d.ScanBand()
+	if err != nil {
+		return Station{}, err
+	}
+	if len(stations) == 0 {
+		return Station{}, ErrSeekFailed
+	}
+	best := stations[0]
+	for _, s := range stations[1:] {
+		if s.RSSI > best.RSSI {
+			best = s
+		}
+	}
+	return best, d.SetFrequency(best.Frequency)
+}
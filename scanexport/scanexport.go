@@ -0,0 +1,146 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package scanexport writes band scan results for upload to FM DX
+// databases such as FMLIST. Frequencies are given in MHz with the precision
+// of the channel grid, PI codes as 4 hex digits and times in UTC. The
+// receiver location is supplied by the caller.
+//
+// CSV has a header line and one row per station:
+//
+//	date,time,frequency,rssi,pi,ps,lat,lon,locator,observer
+//	2024-05-01,18:00:00,90.9,41,C201,BBC R4,51.5072,-0.1276,IO91WM,G4ABC
+//
+// XML wraps the same fields:
+//
+//	<scan observer="G4ABC" lat="51.5072" lon="-0.1276" locator="IO91WM">
+//	  <station date="2024-05-01" time="18:00:00" frequency="90.9" rssi="41" pi="C201" ps="BBC R4"/>
+//	</scan>
+package scanexport
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is one station found by a scan.
+type Record struct {
+	Time      time.Time
+	Frequency uint32 // kHz
+	RSSI      uint8  // dBµV
+	PI        uint16 // 0 if no RDS was received
+	PS        string
+}
+
+// Location describes where and by whom the scan was made. All fields are
+// optional.
+type Location struct {
+	Lat, Lon float64 // degrees, 0 for both leaves them out
+	Locator  string  // Maidenhead locator
+	Observer string  // name or call sign
+}
+
+// WriteCSV writes records as CSV.
+func WriteCSV(w io.Writer, loc Location, records []Record) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "time", "frequency", "rssi", "pi", "ps", "lat", "lon", "locator", "observer"})
+	lat, lon := loc.coordinates()
+	for _, r := range records {
+		t := r.Time.UTC()
+		cw.Write([]string{
+			t.Format("2006-01-02"),
+			t.Format("15:04:05"),
+			MHz(r.Frequency),
+			strconv.Itoa(int(r.RSSI)),
+			pi(r.PI),
+			r.PS,
+			lat,
+			lon,
+			loc.Locator,
+			loc.Observer,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type xmlScan struct {
+	XMLName  xml.Name     `xml:"scan"`
+	Observer string       `xml:"observer,attr,omitempty"`
+	Lat      string       `xml:"lat,attr,omitempty"`
+	Lon      string       `xml:"lon,attr,omitempty"`
+	Locator  string       `xml:"locator,attr,omitempty"`
+	Stations []xmlStation `xml:"station"`
+}
+
+type xmlStation struct {
+	Date      string `xml:"date,attr"`
+	Time      string `xml:"time,attr"`
+	Frequency string `xml:"frequency,attr"`
+	RSSI      int    `xml:"rssi,attr"`
+	PI        string `xml:"pi,attr,omitempty"`
+	PS        string `xml:"ps,attr,omitempty"`
+}
+
+// WriteXML writes records as XML.
+func WriteXML(w io.Writer, loc Location, records []Record) error {
+	scan := xmlScan{Observer: loc.Observer, Locator: loc.Locator}
+	scan.Lat, scan.Lon = loc.coordinates()
+	for _, r := range records {
+		t := r.Time.UTC()
+		scan.Stations = append(scan.Stations, xmlStation{
+			Date:      t.Format("2006-01-02"),
+			Time:      t.Format("15:04:05"),
+			Frequency: MHz(r.Frequency),
+			RSSI:      int(r.RSSI),
+			PI:        pi(r.PI),
+			PS:        r.PS,
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(scan); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// MHz formats a frequency in kHz as MHz with as many decimals as needed,
+// 90.9 on a 100 kHz grid and 90.95 or 90.925 on finer ones.
+func MHz(khz uint32) string {
+	frac := strconv.FormatUint(uint64(khz%1000), 10)
+	frac = strings.TrimRight(strings.Repeat("0", 3-len(frac))+frac, "0")
+	if frac == "" {
+		frac = "0"
+	}
+	return strconv.FormatUint(uint64(khz/1000), 10) + "." + frac
+}
+
+func (l Location) coordinates() (lat, lon string) {
+	if l.Lat == 0 && l.Lon == 0 {
+		return "", ""
+	}
+	return strconv.FormatFloat(l.Lat, 'f', 4, 64), strconv.FormatFloat(l.Lon, 'f', 4, 64)
+}
+
+func pi(v uint16) string {
+	if v == 0 {
+		return ""
+	}
+	h := strings.ToUpper(strconv.FormatUint(uint64(v), 16))
+	return strings.Repeat("0", 4-len(h)) + h
+}
@@ -0,0 +1,179 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package schedule tunes stations at set times of the week, like the timer
+// of a recording radio, and reports when each programme starts and stops.
+//
+//	s := schedule.New(&fm)
+//	s.Add(schedule.Entry{
+//		Name:      "evening news",
+//		Frequency: 90900,
+//		Days:      schedule.Weekdays,
+//		Hour:      18,
+//		Duration:  30 * time.Minute,
+//	})
+//	s.OnEvent = func(e schedule.Event) { ... start or stop the recorder ... }
+//	s.Run(time.Second)
+//
+// The scheduler uses time.Now, so on microcontrollers the clock must be set
+// first, for example from RDS clock time.
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// Days is a set of weekdays.
+type Days uint8
+
+const (
+	Sunday Days = 1 << iota
+	Monday
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+
+	Weekdays = Monday | Tuesday | Wednesday | Thursday | Friday
+	Weekend  = Saturday | Sunday
+	Everyday = Weekdays | Weekend
+)
+
+func (d Days) has(day time.Weekday) bool {
+	return d&(1<<uint(day)) != 0
+}
+
+// Entry is one recurring programme.
+type Entry struct {
+	Name      string
+	Frequency uint32 // kHz
+	Days      Days
+	Hour      int
+	Minute    int
+	Duration  time.Duration
+}
+
+// start returns the start of the occurrence of e that covers t, if any.
+func (e *Entry) start(t time.Time) (time.Time, bool) {
+	// an occurrence may have started yesterday and run past midnight
+	for _, offset := range []int{0, -1} {
+		day := t.AddDate(0, 0, offset)
+		if !e.Days.has(day.Weekday()) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), e.Hour, e.Minute, 0, 0, t.Location())
+		if !t.Before(start) && t.Before(start.Add(e.Duration)) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+type EventType uint8
+
+const (
+	Start EventType = iota
+	Stop
+)
+
+// Event reports that a scheduled programme started or stopped.
+type Event struct {
+	Type  EventType
+	Entry Entry
+	Time  time.Time
+}
+
+// Scheduler tunes the device according to its entries. When a programme
+// ends the station that was playing before it is restored.
+type Scheduler struct {
+	OnEvent func(Event)
+
+	dev      *si4703.Device
+	entries  []Entry
+	active   int
+	started  time.Time
+	previous uint32
+}
+
+func New(dev *si4703.Device) *Scheduler {
+	return &Scheduler{
+		dev:    dev,
+		active: -1,
+	}
+}
+
+// Add schedules a new entry. When entries overlap, the one added first wins.
+func (s *Scheduler) Add(e Entry) {
+	s.entries = append(s.entries, e)
+}
+
+// Check starts or stops programmes as needed for the time now. If the
+// device fails nothing changes, so the next Check tries again.
+func (s *Scheduler) Check(now time.Time) error {
+	if s.active >= 0 {
+		e := &s.entries[s.active]
+		if start, ok := e.start(now); ok && start.Equal(s.started) {
+			return nil
+		}
+		if err := s.dev.SetFrequency(s.previous); err != nil {
+			return err
+		}
+		s.active = -1
+		s.emit(Event{Type: Stop, Entry: *e, Time: now})
+	}
+	for i := range s.entries {
+		e := &s.entries[i]
+		start, ok := e.start(now)
+		if !ok {
+			continue
+		}
+		status, err := s.dev.Status()
+		if err != nil {
+			return err
+		}
+		if err := s.dev.SetFrequency(e.Frequency); err != nil {
+			return err
+		}
+		s.active = i
+		s.started = start
+		s.previous = status.Frequency
+		s.emit(Event{Type: Start, Entry: *e, Time: now})
+		return nil
+	}
+	return nil
+}
+
+// Run calls Check every interval until the device fails.
+func (s *Scheduler) Run(interval time.Duration) error {
+	return s.RunContext(context.Background(), interval)
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (s *Scheduler) RunContext(ctx context.Context, interval time.Duration) error {
+	for {
+		if err := s.Check(time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (s *Scheduler) emit(e Event) {
+	if s.OnEvent != nil {
+		s.OnEvent(e)
+	}
+}
@@ -0,0 +1,32 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// SeekMode is what a seek does at the end of the band, the SKMODE bit of
+// POWERCFG.
+type SeekMode uint8
+
+const (
+	// SeekWrap carries on from the other end of the band, failing only
+	// after a full circle without a station. It is the power-up default.
+	SeekWrap SeekMode = iota
+	// SeekStop stops at the band limit, where the seek fails with
+	// ErrSeekFailed.
+	SeekStop
+)
+
+// SetSeekMode sets whether seeks wrap around or stop at the band limit.
+func (d *Device) SetSeekMode(mode SeekMode) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("seek mode", func() {
+		d.registers[POWERCFG] = d.registers[POWERCFG]&^(1<<SKMODE) | uint16(mode&0x1)<<SKMODE
+	})
+}
@@ -0,0 +1,98 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "errors"
+
+// ErrInvalidSeekProfile is returned by SetSeekProfile for an unknown
+// profile.
+var ErrInvalidSeekProfile = errors.New("si4703: invalid seek profile")
+
+// ErrNotSupported is returned for settings the chip revision lacks.
+var ErrNotSupported = errors.New("si4703: not supported by this chip revision")
+
+// SeekProfile is a named combination of the seek RSSI threshold (SEEKTH),
+// SNR threshold (SKSNR) and FM impulse detection count (SKCNT).
+type SeekProfile uint8
+
+const (
+	// SeekSensitive stops on weak stations too, for DX and poor antennas.
+	SeekSensitive SeekProfile = iota
+	// SeekStandard uses the settings recommended in the datasheet.
+	SeekStandard
+	// SeekSelective only stops on strong, clean stations, for noisy
+	// environments where seeks otherwise stop on noise.
+	SeekSelective
+)
+
+var seekProfiles = [...]struct {
+	seekth byte // minimum RSSI
+	sksnr  byte // minimum SNR, 1 (lenient) to 15 (strict), 0 disabled
+	skcnt  byte // impulses allowed, 1 (strict) to 15 (lenient), 0 disabled
+}{
+	SeekSensitive: {0x0C, 0x3, 0xC},
+	SeekStandard:  {0x19, 0x4, 0x8},
+	SeekSelective: {0x23, 0x7, 0x4},
+}
+
+// SetSeekProfile sets the thresholds used to decide whether a seek should
+// stop on a channel.
+func (d *Device) SetSeekProfile(p SeekProfile) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if int(p) >= len(seekProfiles) {
+		return ErrInvalidSeekProfile
+	}
+	profile := seekProfiles[p]
+	return d.modify("seek profile", func() {
+		d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2]&0x00FF | uint16(profile.seekth)<<SEEKTH
+		if !d.hasQuirk(quirkNoSeekQuality) {
+			d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3]&0xFF00 | uint16(profile.sksnr)<<SKSNR | uint16(profile.skcnt)<<SKCNT
+		}
+	})
+}
+
+// SetSeekThreshold sets the minimum RSSI a seek stops on (SEEKTH), 0 to
+// 127. Raise it where seeks stop on noise.
+func (d *Device) SetSeekThreshold(rssi uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("seek threshold", func() {
+		d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2]&0x00FF | uint16(rssi&0x7F)<<SEEKTH
+	})
+}
+
+// SetSeekSNR sets the minimum SNR a seek stops on (SKSNR), from 1
+// (lenient) to 15 (strict), or 0 to disable the check. It needs a Rev C
+// chip or later.
+func (d *Device) SetSeekSNR(snr uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.hasQuirk(quirkNoSeekQuality) && snr != 0 {
+		return ErrNotSupported
+	}
+	return d.modify("seek snr", func() {
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3]&^(0xF<<SKSNR) | uint16(snr&0xF)<<SKSNR
+	})
+}
+
+// SetSeekImpulseCount sets how many FM impulses a seek tolerates on a
+// channel (SKCNT), from 1 (strict) to 15 (lenient), or 0 to disable the
+// check. It needs a Rev C chip or later.
+func (d *Device) SetSeekImpulseCount(count uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.hasQuirk(quirkNoSeekQuality) && count != 0 {
+		return ErrNotSupported
+	}
+	return d.modify("seek impulse count", func() {
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3]&^(0xF<<SKCNT) | uint16(count&0xF)<<SKCNT
+	})
+}
@@ -0,0 +1,34 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// SetSeekProgress makes seeks call f with each frequency in kHz the chip
+// passes, read from READCHAN while waiting for the seek to complete, so a
+// display can show the dial sweeping. It is called with the device locked,
+// so f must not call back into the device. Pass nil to stop.
+func (d *Device) SetSeekProgress(f func(khz uint32)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onSeekProgress = f
+}
+
+// seekProgress reports the channel a seek in progress has reached, if it
+// moved since last time.
+func (d *Device) seekProgress() {
+	if d.onSeekProgress == nil {
+		return
+	}
+	channel := d.registers[READCHAN] & 0x1FF
+	if channel == d.seekChannel {
+		return
+	}
+	d.seekChannel = channel
+	d.onSeekProgress(d.channelToFrequency(channel))
+}
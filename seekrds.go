@@ -0,0 +1,131 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// SeekTP seeks in dir, 1 for up and 0 for down, to the next station that
+// broadcasts the traffic programme flag. Each station the seek stops on is
+// listened to briefly to verify the flag. It reports whether one was found,
+// otherwise it goes back to the station it started from.
+func (d *Device) SeekTP(dir byte) (bool, error) {
+	return d.seekRDS(dir, func(data RDSData) bool {
+		return data.TrafficProgram
+	})
+}
+
+// SeekPTY seeks in dir, 1 for up and 0 for down, to the next station
+// broadcasting programme type pty, such as 1 for News in RDS. Each station
+// the seek stops on is listened to briefly to verify the programme type.
+// It reports whether one was found, otherwise it goes back to the station
+// it started from.
+func (d *Device) SeekPTY(pty uint8, dir byte) (bool, error) {
+	return d.seekRDS(dir, func(data RDSData) bool {
+		return data.ProgramType == pty
+	})
+}
+
+// seekRDS seeks in dir until match accepts the RDS of a station, giving up
+// once the seek comes back around to the start.
+func (d *Device) seekRDS(dir byte, match func(RDSData) bool) (bool, error) {
+	status, err := d.Status()
+	if err != nil {
+		return false, err
+	}
+	start := status.Frequency
+	first := uint32(0)
+	for {
+		freq, err := d.Seek(dir)
+		if err == ErrSeekAborted {
+			return false, nil
+		} else if err == ErrSeekFailed {
+			break
+		} else if err != nil {
+			return false, err
+		}
+		if freq == first || freq == start && first != 0 {
+			break
+		}
+		if first == 0 {
+			first = freq
+		}
+		if ok, err := d.verifyRDS(match); ok || err != nil {
+			return ok, err
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return false, d.setFrequency(context.Background(), start)
+}
+
+// rdsRejectGroups is how many groups verifyRDS receives without a match
+// before it rejects a station. Programme type, TP and PI come with every
+// group, so there is no point listening for the whole RDSVerify timing.
+const rdsRejectGroups = 4
+
+// verifyRDS reads RDS for up to the RDSVerify timing and reports whether
+// match accepted it. Stations without RDS are rejected, as are stations
+// whose first few groups do not match.
+func (d *Device) verifyRDS(match func(RDSData) bool) (bool, error) {
+	t := d.Timings()
+	deadline := time.Now().Add(t.RDSVerify)
+	groups := 0
+	for time.Now().Before(deadline) {
+		_, ok, err := d.ReadRDS()
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			if match(d.RDSData()) {
+				return true, nil
+			}
+			if groups++; groups == rdsRejectGroups {
+				return false, nil
+			}
+		}
+		time.Sleep(t.RDSPoll)
+	}
+	return false, nil
+}
+
+// FindPI scans the band and tunes to the strongest station broadcasting
+// RDS programme identification pi, to follow a network from one
+// transmitter's coverage area into the next. Stations are listened to
+// briefly, strongest first, to verify the PI. It reports whether one was
+// found, otherwise it goes back to the station it started from.
+func (d *Device) FindPI(pi uint16) (bool, error) {
+	start, err := d.Frequency()
+	if err != nil {
+		return false, err
+	}
+	stations, err := d.ScanBand()
+	if err != nil {
+		return false, err
+	}
+	sort.SliceStable(stations, func(i, j int) bool {
+		return stations[i].RSSI > stations[j].RSSI
+	})
+	for _, s := range stations {
+		if err := d.SetFrequency(s.Frequency); err != nil {
+			return false, err
+		}
+		ok, err := d.verifyRDS(func(data RDSData) bool {
+			return data.PI == pi
+		})
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, d.SetFrequency(start)
+}
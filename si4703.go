@@ -11,15 +11,16 @@ package si4703
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mschoch/go-rds"
 
-	"machine"
-
 	"tinygo.org/x/drivers"
 )
 
@@ -45,6 +46,18 @@ const (
 	RDSD
 )
 
+// SYSCONFIG3 is register 0x06, which holds the soft mute, volume
+// extension and seek quality settings.
+const SYSCONFIG3 = UNUSED6
+
+// TEST1 and TEST2 are registers 0x07 and 0x08. Apart from the oscillator
+// enable written by Configure they are reserved and only read for
+// diagnostics.
+const (
+	TEST1 = UNUSED7
+	TEST2 = UNUSED8
+)
+
 // powercfg
 const SMUTE uint16 = 15
 const DMUTE uint16 = 14
@@ -53,34 +66,100 @@ const RDSMODE uint16 = 11
 const SKMODE uint16 = 10
 const SEEKUP uint16 = 9
 const SEEK uint16 = 8
+const DISABLE uint16 = 6
+const ENABLE uint16 = 0
 
 // channel
 const TUNE uint16 = 15
 
 // sysconfig1
+const RDSIEN uint16 = 15
+const STCIEN uint16 = 14
 const RDS uint16 = 12
 const DE uint16 = 11
-const AGC uint16 = 10
-const BLNDADJ uint16 = 7
+const AGC uint16 = 10 // AGCD, set to disable the AGC
+const BLNDADJ uint16 = 6
+const GPIO3 uint16 = 4
+const GPIO2 uint16 = 2
+const GPIO1 uint16 = 0
 
 // sysconfig2
+const SEEKTH uint16 = 8
+const BAND1 uint16 = 7
+const BAND0 uint16 = 6
 const SPACE1 uint16 = 5
 const SPACE0 uint16 = 4
 
+// sysconfig3
+const SMUTER uint16 = 14
+const SMUTEA uint16 = 12
+const VOLEXT uint16 = 8
+const SKSNR uint16 = 4
+const SKCNT uint16 = 0
+
+// test1
+const XOSCEN uint16 = 15
+const AHIZEN uint16 = 14
+
 // statusrssi
 const RDSR uint16 = 15
 const STC uint16 = 14
 const SFBL uint16 = 13
 const AFCRL uint16 = 12
 const RDSS uint16 = 11
+const BLERA uint16 = 9
 const STEREO uint16 = 8
 
+// readchan
+const BLERB uint16 = 14
+const BLERC uint16 = 12
+const BLERD uint16 = 10
+
+// block error count meaning too many errors to correct, in verbose RDS mode
+const blerUncorrectable = 0x3
+
+// Device is an Si4703 on an I2C bus. Its methods may be called from several
+// goroutines; hooks, sinks and callbacks run with the device locked and must
+// not call back into it.
 type Device struct {
-	bus       drivers.I2C
-	addr      uint16
-	registers []uint16
-	rdsinfo   *rds.RDSInfo
-	reset     machine.Pin
+	mu         sync.Mutex
+	bus        drivers.I2C
+	addr       uint16
+	registers  []uint16
+	rdsinfo    *rds.RDSInfo
+	decoder    rdsDecoder
+	rdsQuality rdsQualityMeter
+	tmc        tmcTracker
+	tmcSink    TMCSink
+	amp        AmpHooks
+	metrics    Metrics
+	reset      Pin
+	logger     Logger
+	logLevels  [numSubsystems]Level
+	quirks     uint16
+	rssiCal    RSSICalibration
+	timings    Timings
+	config     Config
+	irq        chan struct{}
+	irqSTC     bool
+	irqRDS     bool
+	// set while waitSTC waits for an STC interrupt, which ServeInterrupts
+	// then hands over on stcIRQ
+	stcWaiting atomic.Bool
+	stcIRQ     chan struct{}
+	// closed by StopScan while ScanPreview runs
+	scanStop chan struct{}
+	abort    <-chan struct{}
+	// the seek or tune started by StartSeek or StartTune
+	pending pendingOp
+	// SetSeekProgress callback and the channel last reported to it
+	onSeekProgress func(khz uint32)
+	seekChannel    uint16
+	onClockTime    func(time.Time)
+	// called as traffic announcements start and end
+	onTrafficAnnouncement func(active bool)
+	// the standard RDS data is interpreted with, see SetRDSStandard
+	standard RDSStandard
 }
 
 func New(bus drivers.I2C) Device {
@@ -88,11 +167,77 @@ func New(bus drivers.I2C) Device {
 		bus:       bus,
 		addr:      I2C_ADDR,
 		registers: make([]uint16, 16),
-		reset:     machine.Pin(machine.GPIO15),
+		reset:     defaultResetPin(),
+		logger:    PrintLogger{},
+		logLevels: [numSubsystems]Level{DefaultLogLevel, DefaultLogLevel, DefaultLogLevel},
+		irq:       make(chan struct{}, 1),
+		stcIRQ:    make(chan struct{}, 1),
+		timings:   DefaultTimings(),
+	}
+}
+
+// Configure resets and powers up the chip with cfg, all in one power-up
+// sequence.
+func (d *Device) Configure(cfg Config) error {
+	return d.ConfigureContext(context.Background(), cfg)
+}
+
+// ConfigureContext is Configure, giving up between the steps of the power
+// up sequence once ctx is done.
+func (d *Device) ConfigureContext(ctx context.Context, cfg Config) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if cfg.NoReset {
+		d.reset = nil
+	} else if cfg.ResetPin != nil {
+		d.reset = cfg.ResetPin
+	}
+	d.config = cfg
+	if err := d.powerUp(ctx); err != nil {
+		return d.registerError("configure", err)
+	}
+	if err := d.readRegisters(); err != nil {
+		return d.registerError("configure", err)
+	}
+	if err := d.applyQuirks(); err != nil {
+		return d.registerError("configure", err)
+	}
+	if cfg.ResumeFrom != nil {
+		return d.resume(cfg.ResumeFrom)
+	}
+	return nil
+}
+
+// pulseReset takes RST low and back high, holding SDIO low meanwhile if
+// the board gave its pin so the chip comes up in 2-wire mode.
+func (d *Device) pulseReset(ctx context.Context) error {
+	sdio := d.config.SDIOPin
+	if sdio != nil {
+		configureOutput(sdio)
+		sdio.Low()
+	}
+	configureOutput(d.reset)
+
+	d.reset.Low()
+	err := sleep(ctx, d.timings.Reset)
+	d.reset.High()
+	if err == nil {
+		err = sleep(ctx, d.timings.Reset)
+	}
+	if sdio == nil {
+		return err
 	}
+	sdio.High()
+	if d.config.RestoreBus != nil {
+		if rerr := d.config.RestoreBus(); err == nil {
+			err = rerr
+		}
+	}
+	return err
 }
 
-func (d *Device) Configure() (err error) {
+// powerUp resets the chip, starts the oscillator and enables the IC.
+func (d *Device) powerUp(ctx context.Context) error {
 	d.rdsinfo = rds.NewRDSInfo()
 
 	// do some manual GPIO to initialize the device
@@ -101,68 +246,159 @@ func (d *Device) Configure() (err error) {
 	// 	return err
 	// }
 
-	d.reset.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	// keep the amplifier quiet while the chip powers up, it comes up muted
+	d.onMute(true)
 
-	d.reset.Low()
-	time.Sleep(1 * time.Second)
-	d.reset.High()
-	time.Sleep(1 * time.Second)
+	// without a reset pin the board is expected to have reset the chip
+	if d.reset != nil {
+		if err := d.pulseReset(ctx); err != nil {
+			return err
+		}
+	}
 
 	// read
-	d.readRegisters()
+	if err := d.readRegisters(); err != nil {
+		return err
+	}
 	// enable the oscillator
-	d.registers[UNUSED7] = 0x8100
+	// the reserved bits must be written as 0x0100
+	d.registers[TEST1] = (1 << XOSCEN) | 0x0100
 	// update
-	d.updateRegisters()
+	if err := d.updateRegisters(); err != nil {
+		return err
+	}
 
 	// wait for clock to settle
-	time.Sleep(500 * time.Millisecond)
+	if err := sleep(ctx, d.timings.OscillatorSettle); err != nil {
+		return err
+	}
 
 	// read
-	d.readRegisters()
-	// enable the IC
+	if err := d.readRegisters(); err != nil {
+		return err
+	}
+	// enable the IC, configured
 	d.registers[POWERCFG] = 0x0001
-	d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1] | (1 << RDS)
-	d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2] & 0xFFF0 // clear volume
-	d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2] | 0x0001 // set to lowest
+	d.applyConfig()
 	// update
-	d.updateRegisters()
+	if err := d.updateRegisters(); err != nil {
+		return err
+	}
 
 	// wait max powerup time
-	time.Sleep(110 * time.Millisecond)
-
-	return
+	return sleep(ctx, d.timings.PowerUp)
 }
 
 func (d *Device) Close() error {
-	println("turning off chip")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log(SubsystemTune, LevelInfo, "turning off chip")
+	d.onMute(true)
 	// read
-	d.readRegisters()
+	if err := d.readRegisters(); err != nil {
+		return d.registerError("close", err)
+	}
 	// disable the IC
 	d.registers[POWERCFG] = 0x0000
-	d.updateRegisters()
+	if err := d.updateRegisters(); err != nil {
+		return d.registerError("close", err)
+	}
 	return nil
 }
 
-func (d *Device) DisableSoftMute() {
-	d.readRegisters()
-	d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << SMUTE)
-	d.updateRegisters()
+func (d *Device) DisableSoftMute() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("soft mute", func() {
+		d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << SMUTE)
+	})
+}
+
+func (d *Device) DisableMute() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.modify("unmute", func() {
+		d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << DMUTE)
+	})
+	if err != nil {
+		return err
+	}
+	d.onMute(false)
+	return nil
+}
+
+func (d *Device) EnableMute() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onMute(true)
+	return d.modify("mute", func() {
+		d.registers[POWERCFG] = d.registers[POWERCFG] & 0xBFFF
+	})
+}
+
+// Muted reports whether the audio output is currently muted.
+func (d *Device) Muted() (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return false, d.registerError("read mute", err)
+	}
+	return d.registers[POWERCFG]&(1<<DMUTE) == 0, nil
+}
+
+// ForceMono sets FORCEMONO so the audio stays mono whatever the signal,
+// which greatly reduces hiss on weak stations.
+func (d *Device) ForceMono() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("force mono", func() {
+		d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << FORCEMONO)
+	})
+}
+
+// AllowStereo clears FORCEMONO, letting the chip blend to stereo again.
+func (d *Device) AllowStereo() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("allow stereo", func() {
+		d.registers[POWERCFG] = d.registers[POWERCFG] &^ (1 << FORCEMONO)
+	})
 }
 
-func (d *Device) DisableMute() {
-	d.readRegisters()
-	d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << DMUTE)
-	d.updateRegisters()
+// EnableAGC clears AGCD, turning the automatic gain control back on.
+func (d *Device) EnableAGC() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("agc", func() {
+		d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1] &^ (1 << AGC)
+	})
 }
 
-func (d *Device) EnableMute() {
-	d.readRegisters()
-	d.registers[POWERCFG] = d.registers[POWERCFG] & 0xBFFF
-	d.updateRegisters()
+// DisableAGC sets AGCD, turning off the automatic gain control. Do this
+// when an external LNA is fitted, which can otherwise overload the front
+// end.
+func (d *Device) DisableAGC() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("agc", func() {
+		d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1] | (1 << AGC)
+	})
 }
 
-func (d *Device) readRegisters() {
+// modify reads the registers, lets change edit the shadow copy and writes
+// them back, returning a RegisterError for op if either transfer fails.
+func (d *Device) modify(op string, change func()) error {
+	if err := d.readRegisters(); err != nil {
+		return d.registerError(op, err)
+	}
+	change()
+	if err := d.updateRegisters(); err != nil {
+		return d.registerError(op, err)
+	}
+	return nil
+}
+
+func (d *Device) readRegisters() error {
 
 	// with i2c we first write an address we want to read
 	// however, this device interprets that address
@@ -177,11 +413,16 @@ func (d *Device) readRegisters() {
 
 	data := make([]byte, 32)
 	var err error
+	d.count(MetricReads)
 	if err = d.bus.Tx(d.addr, bufbytes, data); err != nil {
-		return
+		d.count(MetricBusErrors)
+		d.log(SubsystemBus, LevelError, "error reading: "+err.Error())
+		return err
 	}
 
-	println("read bytes", data)
+	if d.logs(SubsystemBus, LevelTrace) {
+		d.log(SubsystemBus, LevelTrace, "read bytes "+hexBytes(data))
+	}
 
 	counter := 0
 	for x := 0x0A; ; x++ {
@@ -191,137 +432,489 @@ func (d *Device) readRegisters() {
 		p := bytes.NewBuffer(data[counter : counter+2])
 		err = binary.Read(p, binary.BigEndian, &d.registers[x])
 		if err != nil {
-			println("error reading:", err)
-			return
+			d.log(SubsystemBus, LevelError, "error reading: "+err.Error())
+			return err
 		}
 		counter = counter + 2
 		if x == 0x09 {
 			break
 		}
 	}
-
-	println("self: ", d)
+	return nil
 }
 
-func (d *Device) updateRegisters() {
+func (d *Device) updateRegisters() error {
 	p := new(bytes.Buffer)
 	for x := 0x02; x < 0x08; x++ {
 		binary.Write(p, binary.BigEndian, d.registers[x])
 	}
 
 	bytes := p.Bytes()
-	println("output bytes is", bytes)
+	if d.logs(SubsystemBus, LevelTrace) {
+		d.log(SubsystemBus, LevelTrace, "output bytes "+hexBytes(bytes))
+	}
 
+	d.count(MetricWrites)
 	err := d.bus.Tx(d.addr, bytes, bytes[1:])
 	if err != nil {
-		println("error writing: ", err)
+		d.count(MetricBusErrors)
+		d.log(SubsystemBus, LevelError, "error writing: "+err.Error())
 	}
 
 	//d.readRegisters()
+	return err
 }
 
-func (d *Device) SetVolume(volume uint16) {
-	d.readRegisters()
+// SetVolume sets the volume from 0 (muted) to 15 (full scale), turning
+// off the extended range of SetVolumeExtended.
+func (d *Device) SetVolume(volume uint16) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	if volume < 0 {
 		volume = 0
 	}
 	if volume > 15 {
 		volume = 15
 	}
-	d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2] & 0xFFF0
-	d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2] | volume
-	d.updateRegisters()
+	return d.modify("volume", func() {
+		d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2] & 0xFFF0
+		d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2] | volume
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3] &^ (1 << VOLEXT)
+	})
+}
+
+// Volume returns the current volume setting, 0 to 15.
+func (d *Device) Volume() (uint16, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return 0, d.registerError("read volume", err)
+	}
+	return d.registers[SYSCONFIG2] & 0x000F, nil
+}
+
+// SetChannel tunes to channel, given in 100 kHz units such as 909 for
+// 90.9 MHz.
+func (d *Device) SetChannel(channel uint16) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.setFrequency(context.Background(), uint32(channel)*100)
+}
+
+// SetFrequency tunes to the channel nearest to khz, such as 90900 for
+// 90.9 MHz, using the band and spacing in use.
+func (d *Device) SetFrequency(khz uint32) error {
+	return d.TuneContext(context.Background(), khz)
+}
+
+// Frequency reads back the tuned frequency in kHz from READCHAN.
+func (d *Device) Frequency() (uint32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return 0, d.registerError("read frequency", err)
+	}
+	return d.channelToFrequency(d.registers[READCHAN] & 0x1FF), nil
+}
+
+// TuneUp tunes one channel spacing up from the current channel, wrapping
+// from the top of the band to the bottom, and returns the new frequency in
+// kHz.
+func (d *Device) TuneUp() (uint32, error) {
+	return d.tuneStep(true)
+}
+
+// TuneDown tunes one channel spacing down, wrapping from the bottom of the
+// band to the top, and returns the new frequency in kHz.
+func (d *Device) TuneDown() (uint32, error) {
+	return d.tuneStep(false)
+}
+
+func (d *Device) tuneStep(up bool) (uint32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return 0, d.registerError("tune", err)
+	}
+	channel := d.registers[READCHAN] & 0x1FF
+	last := d.frequencyToChannel(d.band().Top())
+	switch {
+	case up && channel >= last:
+		channel = 0
+	case up:
+		channel++
+	case channel == 0:
+		channel = last
+	default:
+		channel--
+	}
+	khz := d.channelToFrequency(channel)
+	return khz, d.setFrequency(context.Background(), khz)
 }
 
-func (d *Device) SetChannel(channel uint16) {
-	newChannel := channel * 10
-	newChannel = newChannel - 8750
-	newChannel = newChannel / 20
+// TuneContext tunes to the channel nearest to khz, giving the tune up and
+// returning ctx.Err() once ctx is done.
+func (d *Device) TuneContext(ctx context.Context, khz uint32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.setFrequency(ctx, khz)
+}
 
-	d.readRegisters()
+// setFrequency tunes to the channel nearest to khz.
+func (d *Device) setFrequency(ctx context.Context, khz uint32) error {
+	if err := d.startTune(khz); err != nil {
+		return d.registerError("tune", err)
+	}
+	err := d.finishTune(ctx)
+	d.postTune()
+	return d.tuneDone(err)
+}
+
+// startTune sets the TUNE bit for the channel nearest to khz. Unless it
+// fails, the caller must call postTune once the tune is over.
+func (d *Device) startTune(khz uint32) error {
+	if err := d.readRegisters(); err != nil {
+		return err
+	}
+	newChannel := d.frequencyToChannel(khz)
 	d.registers[CHANNEL] = d.registers[CHANNEL] & 0xFE00
 	d.registers[CHANNEL] = d.registers[CHANNEL] | newChannel
 	d.registers[CHANNEL] = d.registers[CHANNEL] | (1 << TUNE)
 
-	println("Attempting to tune and fart")
-	d.updateRegisters()
+	d.log(SubsystemTune, LevelDebug, "tuning")
+	d.count(MetricTunes)
+	d.preTune()
+	if err := d.updateRegisters(); err != nil {
+		d.postTune()
+		return err
+	}
+	return nil
+}
 
-	// wait for tuning to complete
-	for {
-		d.readRegisters()
-		if d.registers[STATUSRSSI]&(1<<STC) != 0 {
-			println("Tuning Complete")
-			break
-		}
+// tuneDone logs the outcome of a tune and wraps err as SetChannel returns
+// it.
+func (d *Device) tuneDone(err error) error {
+	if err != nil {
+		return d.registerError("tune", err)
+	}
+	if d.afcRailed() {
+		d.log(SubsystemTune, LevelWarn, "AFC railed, no valid station on "+d.printReadChannel(d.registers[READCHAN]))
 	}
+	if d.logs(SubsystemTune, LevelInfo) {
+		d.log(SubsystemTune, LevelInfo, "tuned to "+d.printReadChannel(d.registers[READCHAN]))
+	}
+	return nil
+}
 
-	// clear out old RDS info
-	d.rdsinfo = rds.NewRDSInfo()
+// maxAFCRetries is how many more times Seek goes on when it stops on a
+// channel with the AFC railed.
+const maxAFCRetries = 3
 
-	// clear the tune bit
-	d.registers[CHANNEL] = d.registers[CHANNEL] &^ (1 << TUNE)
-	d.updateRegisters()
+// Seek seeks up (dir 1) or down (dir 0) to the next station and returns
+// its frequency in kHz. A seek that is aborted or fails still returns the
+// frequency it stopped at, along with ErrSeekAborted or ErrSeekFailed.
+func (d *Device) Seek(dir byte) (uint32, error) {
+	return d.SeekContext(context.Background(), dir)
+}
 
-	// now wait for for STC to be cleared
-	for {
-		d.readRegisters()
-		if d.registers[STATUSRSSI]&(1<<STC) == 0 {
-			println("STC Cleared")
-			break
-		}
+// SeekContext is Seek, stopping the seek where it is and returning
+// ctx.Err() once ctx is done.
+func (d *Device) SeekContext(ctx context.Context, dir byte) (uint32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.seek(ctx, dir)
+	// an AFC railed stop is not a valid station, carry on seeking
+	for i := 0; err == nil && d.afcRailed() && i < maxAFCRetries; i++ {
+		d.log(SubsystemTune, LevelDebug, "AFC railed, seeking on")
+		err = d.seek(ctx, dir)
 	}
+	return d.channelToFrequency(d.registers[READCHAN] & 0x1FF), d.seekDone(err)
+}
 
-	println("Tuned to ", d.printReadChannel(d.registers[READCHAN]))
+// seekDone logs the outcome of a seek and wraps err as Seek returns it.
+func (d *Device) seekDone(err error) error {
+	if err == ErrSeekAborted || err == context.Canceled || err == context.DeadlineExceeded {
+		d.log(SubsystemTune, LevelInfo, "seek aborted at "+d.printReadChannel(d.registers[READCHAN]))
+		return err
+	} else if err == ErrSeekFailed {
+		d.log(SubsystemTune, LevelInfo, "seek failed at "+d.printReadChannel(d.registers[READCHAN]))
+		return err
+	} else if err != nil {
+		return d.registerError("seek", err)
+	}
+	if d.logs(SubsystemTune, LevelInfo) {
+		d.log(SubsystemTune, LevelInfo, "seeked to "+d.printReadChannel(d.registers[READCHAN]))
+	}
+	return nil
+}
+
+// seek runs one seek in dir.
+func (d *Device) seek(ctx context.Context, dir byte) error {
+	if err := d.startSeek(dir); err != nil {
+		return err
+	}
+	err := d.finishSeek(ctx)
+	d.postTune()
+	return err
 }
 
-func (d *Device) Seek(dir byte) {
-	d.readRegisters()
+// startSeek sets the SEEK bit for a seek in dir. Unless it fails, the
+// caller must call postTune once the seek is over.
+func (d *Device) startSeek(dir byte) error {
+	if err := d.readRegisters(); err != nil {
+		return err
+	}
 	if dir == 1 {
-		println("Seeking UP")
+		d.log(SubsystemTune, LevelDebug, "seeking up")
 		d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << SEEKUP)
 	} else {
-		println("Seeking DOWN")
+		d.log(SubsystemTune, LevelDebug, "seeking down")
 		d.registers[POWERCFG] = d.registers[POWERCFG] &^ (1 << SEEKUP)
 	}
 	d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << SEEK)
+	d.seekChannel = d.registers[READCHAN] & 0x1FF
 
 	// start seek
+	d.count(MetricSeeks)
+	d.preTune()
+	if err := d.updateRegisters(); err != nil {
+		d.postTune()
+		return err
+	}
+	return nil
+}
 
-	d.updateRegisters()
+// afcRailed reports whether the AFC railed on the channel last tuned,
+// meaning there is no valid station there, and counts it if so.
+func (d *Device) afcRailed() bool {
+	if d.registers[STATUSRSSI]&(1<<AFCRL) == 0 {
+		return false
+	}
+	d.count(MetricAFCRailed)
+	return true
+}
 
+// finishSeek waits for a seek started by setting the SEEK bit to complete
+// and clears the bit again. Clearing it early, on user input or when the
+// seek times out, stops the seek on the channel it reached and returns
+// ErrSeekAborted or ErrSeekTimeout, and likewise once ctx is done. A seek
+// that stops at the band limit, with SFBL set, returns ErrSeekFailed.
+func (d *Device) finishSeek(ctx context.Context) error {
 	// wait for seek to complete
-	for {
-		d.readRegisters()
-		if d.registers[STATUSRSSI]&(1<<STC) != 0 {
-			println("Seek Complete")
-			break
-		}
+	aborted := d.waitSTC(ctx, true, d.abort, d.timings.SeekTimeout)
+	if aborted == errSTCTimeout {
+		aborted = ErrSeekTimeout
+	} else if aborted != nil && aborted != ErrSeekAborted && aborted != ctx.Err() {
+		return aborted
 	}
+	return d.clearSeek(aborted)
+}
 
-	// clear out old RDS info
-	d.rdsinfo = rds.NewRDSInfo()
+// clearSeek ends a seek that completed, or is given up with result, by
+// clearing the SEEK bit. It returns result, or ErrSeekFailed if the seek
+// completed at the band limit.
+func (d *Device) clearSeek(result error) error {
+	// SFBL is only valid until SEEK is cleared
+	if result == nil && d.registers[STATUSRSSI]&(1<<SFBL) != 0 {
+		result = ErrSeekFailed
+	}
+	d.log(SubsystemTune, LevelDebug, "seek complete")
+	d.resetRDS()
 
 	// clear the seek bit
 	d.registers[POWERCFG] = d.registers[POWERCFG] &^ (1 << SEEK)
+	if err := d.updateRegisters(); err != nil {
+		return err
+	}
+
+	// now wait for for STC to be cleared
+	if err := d.waitSTC(context.Background(), false, nil, d.timings.TuneTimeout); err != nil {
+		return stcTimeout(err, ErrSeekTimeout)
+	}
+	return result
+}
+
+// finishTune waits for a tune started by setting the TUNE bit to complete
+// and clears the bit again, also when the tune times out or ctx is done.
+func (d *Device) finishTune(ctx context.Context) error {
+	// wait for tuning to complete
+	timedOut := d.waitSTC(ctx, true, nil, d.timings.TuneTimeout)
+	if timedOut == errSTCTimeout {
+		timedOut = ErrTuneTimeout
+	} else if timedOut != nil && timedOut != ctx.Err() {
+		return timedOut
+	}
+	return d.clearTune(timedOut)
+}
+
+// clearTune ends a tune that completed, or is given up with result, by
+// clearing the TUNE bit. It returns result.
+func (d *Device) clearTune(result error) error {
+	d.log(SubsystemTune, LevelDebug, "tuning complete")
+	d.resetRDS()
+
+	// clear the tune bit
+	d.registers[CHANNEL] = d.registers[CHANNEL] &^ (1 << TUNE)
+	if err := d.updateRegisters(); err != nil {
+		return err
+	}
 
 	// now wait for for STC to be cleared
+	if err := d.waitSTC(context.Background(), false, nil, d.timings.TuneTimeout); err != nil {
+		return stcTimeout(err, ErrTuneTimeout)
+	}
+	return result
+}
+
+// waitSTC polls the registers until the seek/tune complete bit is set,
+// or cleared. With the STC interrupt enabled it reads them when GPIO2
+// signals instead, directly or handed over by ServeInterrupts, or after
+// the fallback timing. It gives up with
+// ErrSeekAborted as soon as abort signals, with ctx.Err() once ctx is done
+// and with errSTCTimeout once timeout, if not zero, has passed.
+func (d *Device) waitSTC(ctx context.Context, set bool, abort <-chan struct{}, timeout time.Duration) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	var irq, handed chan struct{}
+	delay := d.timings.STCPoll
+	if set && d.irqSTC {
+		irq, handed, delay = d.irq, d.stcIRQ, d.timings.STCInterruptFallback
+		d.stcWaiting.Store(true)
+		defer d.stcWaiting.Store(false)
+	}
 	for {
-		d.readRegisters()
-		if d.registers[STATUSRSSI]&(1<<STC) == 0 {
-			println("STC Cleared")
-			break
+		t := time.NewTimer(delay)
+		select {
+		case <-abort:
+			t.Stop()
+			return ErrSeekAborted
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-irq:
+		case <-handed:
+		case <-t.C:
+		}
+		t.Stop()
+		if err := d.dispatch(); err != nil {
+			return err
 		}
+		if set && d.registers[POWERCFG]&(1<<SEEK) != 0 {
+			d.seekProgress()
+		}
+		if (d.registers[STATUSRSSI]&(1<<STC) != 0) == set {
+			if !set {
+				d.log(SubsystemTune, LevelTrace, "STC cleared")
+			}
+			return nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return errSTCTimeout
+		}
+	}
+}
+
+// resetRDS clears out old RDS info after changing stations.
+func (d *Device) resetRDS() {
+	ta := d.decoder.trafficAnnouncement()
+	d.rdsinfo = rds.NewRDSInfo()
+	d.decoder = rdsDecoder{tuned: d.channelToFrequency(d.registers[READCHAN] & 0x1FF)}
+	d.trafficChanged(ta)
+	d.tmc = tmcTracker{}
+	d.rdsQuality = rdsQualityMeter{}
+}
+
+// Status is a decoded snapshot of the STATUSRSSI and READCHAN registers.
+type Status struct {
+	Frequency         uint32 // currently tuned frequency in kHz
+	RSSI              uint8  // received signal strength in dBµV
+	Stereo            bool
+	RDSReady          bool
+	RDSSynchronized   bool
+	SeekTuneComplete  bool
+	SeekFailBandLimit bool
+	AFCRailed         bool
+}
+
+// Status reads the registers and returns the current tuning status.
+func (d *Device) Status() (Status, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return Status{}, d.registerError("status", err)
+	}
+	status := d.registers[STATUSRSSI]
+	d.gauge(MetricRSSI, float64(d.rssi(status)))
+	return Status{
+		Frequency:         d.channelToFrequency(d.registers[READCHAN] & 0x1FF),
+		RSSI:              d.rssi(status),
+		Stereo:            status>>STEREO&0x1 == 1,
+		RDSReady:          status>>RDSR&0x1 == 1,
+		RDSSynchronized:   status>>RDSS&0x1 == 1,
+		SeekTuneComplete:  status>>STC&0x1 == 1,
+		SeekFailBandLimit: status>>SFBL&0x1 == 1,
+		AFCRailed:         status>>AFCRL&0x1 == 1,
+	}, nil
+}
+
+// TestRegisters is a read-only view of the TEST1 and TEST2 registers, for
+// debugging power-up failures.
+type TestRegisters struct {
+	Test1  uint16
+	Test2  uint16
+	XOSCEN bool // crystal oscillator enabled
+	AHIZEN bool // audio outputs high impedance
+}
+
+// TestRegisters reads the registers and returns the test register values.
+func (d *Device) TestRegisters() (TestRegisters, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return TestRegisters{}, d.registerError("test registers", err)
+	}
+	test1 := d.registers[TEST1]
+	return TestRegisters{
+		Test1:  test1,
+		Test2:  d.registers[TEST2],
+		XOSCEN: test1>>XOSCEN&0x1 == 1,
+		AHIZEN: test1>>AHIZEN&0x1 == 1,
+	}, nil
+}
+
+// channelToFrequency converts a channel number to a frequency in kHz.
+func (d *Device) channelToFrequency(channel uint16) uint32 {
+	return d.band().Bottom() + uint32(channel)*d.spacing().KHz()
+}
+
+// frequencyToChannel converts a frequency in kHz to the nearest channel
+// number on the grid, clamped to the band.
+func (d *Device) frequencyToChannel(khz uint32) uint16 {
+	band := d.band()
+	bottom := band.Bottom()
+	if khz < bottom {
+		return 0
 	}
-	println("Seeked to ", d.printReadChannel(d.registers[READCHAN]))
+	step := d.spacing().KHz()
+	channel := (khz - bottom + step/2) / step
+	if last := (band.Top() - bottom) / step; channel > last {
+		channel = last
+	}
+	return uint16(channel)
 }
 
 func (d *Device) String() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	rv := "--------------------------------------------------------------------------------\n"
 	rv = rv + d.printDeviceID(d.registers[DEVICEID])
 	rv = rv + d.printChipID(d.registers[CHIPID])
 	rv = rv + d.printPowerCfg(d.registers[POWERCFG])
 	rv = rv + d.printChannel(d.registers[CHANNEL])
 	rv = rv + d.printSysConfig1(d.registers[SYSCONFIG1])
+	rv = rv + d.printTest(d.registers[TEST1], d.registers[TEST2])
 	rv = rv + d.printStatusRSSI(d.registers[STATUSRSSI])
 	rv = rv + d.printReadChannel(d.registers[READCHAN])
 	rv = rv + d.printRDS("A", d.registers[RDSA])
@@ -511,23 +1104,7 @@ func (d *Device) printChannel(tune uint16) string {
 }
 
 func (d *Device) printChannelNumber(channel uint16) string {
-	var rv strings.Builder
-	band := 0      // FIXME use actual band
-	spacing := 200 // FIXME use actual spacing
-	switch band {
-	case 0:
-		freq := ((float64(channel) * 20) + 8750) / 100
-		rv.WriteString(strconv.FormatFloat(freq, 'f', 2, 64))
-		rv.WriteString("MHz")
-		return rv.String()
-	case 1:
-		freq := (float64(spacing) * float64(channel)) + 76.0
-		rv.WriteString(strconv.FormatFloat(freq, 'f', 2, 64))
-		rv.WriteString("MHz")
-		return rv.String()
-	default:
-		return "Unknown"
-	}
+	return d.spacing().FormatMHz(d.channelToFrequency(channel)) + "MHz"
 }
 
 func (d *Device) printDeemphasis(de byte) string {
@@ -571,7 +1148,7 @@ func (d *Device) printSysConfig1(sysconf uint16) string {
 	rv.WriteString(d.printDeemphasis(byte(sysconf >> DE & 0x1)))
 	rv.WriteString("\n")
 	rv.WriteString("AGC: ")
-	rv.WriteString(d.printEnabled(byte(sysconf >> AGC & 0x1)))
+	rv.WriteString(d.printEnabled(byte(^sysconf >> AGC & 0x1)))
 	rv.WriteString("\n")
 	rv.WriteString("Stereo/Mono Blend Adjustment: ")
 	rv.WriteString(d.printSMBlend(byte(sysconf >> BLNDADJ & 0x3)))
@@ -580,6 +1157,23 @@ func (d *Device) printSysConfig1(sysconf uint16) string {
 	return rv.String()
 }
 
+func (d *Device) printTest(test1, test2 uint16) string {
+	var rv strings.Builder
+	rv.WriteString("Crystal Oscillator: ")
+	rv.WriteString(d.printEnabled(byte(test1 >> XOSCEN)))
+	rv.WriteString("\n")
+	rv.WriteString("Audio High-Z: ")
+	rv.WriteString(d.printEnabled(byte(test1 >> AHIZEN & 0x1)))
+	rv.WriteString("\n")
+	rv.WriteString("Test1: 0x")
+	rv.WriteString(hex4(test1))
+	rv.WriteString("\n")
+	rv.WriteString("Test2: 0x")
+	rv.WriteString(hex4(test2))
+	rv.WriteString("\n")
+	return rv.String()
+}
+
 func (d *Device) printRDSReady(rdsr byte) string {
 	switch rdsr {
 	case 0x0:
@@ -662,12 +1256,124 @@ func (d *Device) printReadChannel(readChannel uint16) string {
 	return rv.String()
 }
 
-func (d *Device) PollRDS() {
+// RDSGroup holds the four blocks of a single received RDS group.
+type RDSGroup struct {
+	A, B, C, D uint16
+}
+
+// ReadRDS reads the registers once and, if the chip signals that a new
+// group is ready, feeds it to the RDS decoder and returns it.
+func (d *Device) ReadRDS() (RDSGroup, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return RDSGroup{}, false, d.registerError("read rds", err)
+	}
+	g, ok := d.receiveRDS()
+	return g, ok, nil
+}
+
+// receiveRDS decodes the group in the registers last read, if there is one.
+func (d *Device) receiveRDS() (RDSGroup, bool) {
+	if byte(d.registers[STATUSRSSI]>>RDSR) != 1 {
+		return RDSGroup{}, false
+	}
+	g := RDSGroup{
+		A: d.registers[RDSA],
+		B: d.registers[RDSB],
+		C: d.registers[RDSC],
+		D: d.registers[RDSD],
+	}
+	if reason := d.dropRDS(g); reason != "" {
+		if d.logs(SubsystemRDS, LevelTrace) {
+			d.log(SubsystemRDS, LevelTrace, "dropped group "+g.String()+": "+reason)
+		}
+		d.count(MetricRDSDropped)
+		return RDSGroup{}, false
+	}
+	if d.logs(SubsystemRDS, LevelTrace) {
+		d.log(SubsystemRDS, LevelTrace, "group "+g.String())
+	}
+	d.rdsinfo.Update(g.A, g.B, g.C, g.D)
+	d.count(MetricRDSGroups)
+	d.rdsQuality.add(time.Now())
+	ecc, ct := d.decoder.ecc, d.decoder.ct
+	ta := d.decoder.trafficAnnouncement()
+	d.decoder.update(g)
+	d.trafficChanged(ta)
+	if d.decoder.ecc != ecc {
+		d.checkRegion()
+	}
+	if d.onClockTime != nil && !d.decoder.ct.Equal(ct) {
+		d.onClockTime(d.decoder.ct)
+	}
+	if d.tmcSink != nil && d.tmc.isTMC(g) {
+		if err := d.tmcSink.TMCGroup(g); err != nil {
+			d.log(SubsystemRDS, LevelWarn, "error forwarding TMC group: "+err.Error())
+		}
+	}
+	return g, true
+}
+
+// RDSData returns the RDS information decoded so far for the tuned station.
+func (d *Device) RDSData() RDSData {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.decoder.data()
+}
+
+// StationName returns the programme service name once each of its
+// characters has been received the same twice in a row, which filters out
+// the garbled names single corrupted groups produce. It returns the last
+// name confirmed so, or "" and false before the first.
+func (d *Device) StationName() (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.decoder.psOK {
+		return "", false
+	}
+	return rdsString(d.decoder.psName[:]), true
+}
+
+// RadioText returns the radiotext received so far and whether it is
+// complete, every segment up to its end having arrived. Segments not yet
+// received read as spaces.
+func (d *Device) RadioText() (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return rdsString(d.decoder.rt[:]), d.decoder.rtComplete()
+}
+
+// PollRDS reads RDS groups forever, returning only if reading fails.
+// With the RDS interrupt enabled, see SetInterrupts and UseInterruptPin,
+// it reads only when GPIO2 signals a new group instead of every RDSPoll.
+func (d *Device) PollRDS() error {
+	return d.PollRDSContext(context.Background())
+}
+
+// PollRDSContext is PollRDS until ctx is done, when it returns ctx.Err().
+func (d *Device) PollRDSContext(ctx context.Context) error {
 	for {
+		d.mu.Lock()
+		var tick <-chan time.Time
+		irq := d.irq
+		if !d.irqRDS {
+			tick, irq = time.After(d.timings.RDSPoll), nil
+		}
+		d.mu.Unlock()
 		select {
-		case <-time.After(40 * time.Millisecond):
-			d.readRegisters()
-			if byte(d.registers[STATUSRSSI]>>RDSR) == 1 {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-irq:
+			if err := d.serveInterrupt(); err != nil {
+				return err
+			}
+		case <-tick:
+			_, ok, err := d.ReadRDS()
+			if err != nil {
+				return err
+			}
+			if ok {
 				// d.rdsinfo.PI = d.registers[RDSA]
 				// d.rdsinfo.ProgramType = d.registers[RDSB] >> 5 & 0x1F
 				// rv := "RDS Ready\n"
@@ -681,8 +1387,6 @@ func (d *Device) PollRDS() {
 				// rv = rv + fmt.Sprintf("Traffic Program Code: %d\n", d.registers[RDSB]>>10&0x1)
 				// rv = rv + fmt.Sprintf("Program Type: %d\n", d.registers[RDSB]>>5&0x1F)
 				//fmt.Printf("%s", rv)
-				d.rdsinfo.Update(d.registers[RDSA], d.registers[RDSB], d.registers[RDSC], d.registers[RDSD])
-				println("%v\n", d.rdsinfo)
 			}
 		}
 	}
@@ -0,0 +1,53 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// SoftMuteRate is how quickly the chip attenuates the audio as the signal
+// weakens, the SMUTER bits of SYSCONFIG3.
+type SoftMuteRate uint8
+
+const (
+	SoftMuteFastest SoftMuteRate = iota // the power-up default
+	SoftMuteFast
+	SoftMuteSlow
+	SoftMuteSlowest
+)
+
+// SoftMuteAttenuation is how far the audio is attenuated at the weakest
+// signal, the SMUTEA bits of SYSCONFIG3.
+type SoftMuteAttenuation uint8
+
+const (
+	SoftMute16dB SoftMuteAttenuation = iota // the power-up default
+	SoftMute14dB
+	SoftMute12dB
+	SoftMute10dB
+)
+
+// EnableSoftMute turns soft mute back on after DisableSoftMute.
+func (d *Device) EnableSoftMute() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("soft mute", func() {
+		d.registers[POWERCFG] = d.registers[POWERCFG] &^ (1 << SMUTE)
+	})
+}
+
+// SetSoftMute sets how quickly and how far soft mute attenuates weak
+// stations. Slower and shallower settings keep weak stations listenable at
+// the cost of more audible noise.
+func (d *Device) SetSoftMute(rate SoftMuteRate, attenuation SoftMuteAttenuation) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modify("soft mute", func() {
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3]&^(0x3<<SMUTER|0x3<<SMUTEA) |
+			uint16(rate&0x3)<<SMUTER | uint16(attenuation&0x3)<<SMUTEA
+	})
+}
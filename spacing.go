@@ -0,0 +1,86 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrInvalidSpacing is returned by SetSpacing for the reserved spacing
+// value.
+var ErrInvalidSpacing = errors.New("si4703: invalid spacing")
+
+// Spacing is the channel grid, set with the SPACE bits of SYSCONFIG2.
+// Seeks step along the grid and tunes snap to it.
+type Spacing uint8
+
+const (
+	Spacing200kHz Spacing = iota // the Americas, the power-up default
+	Spacing100kHz                // Europe and Japan
+	Spacing50kHz                 // Italy
+)
+
+// KHz returns the distance between channels.
+func (s Spacing) KHz() uint32 {
+	switch s {
+	case Spacing100kHz:
+		return 100
+	case Spacing50kHz:
+		return 50
+	default:
+		return 200
+	}
+}
+
+// FormatMHz formats a frequency in kHz as MHz with the decimals the grid
+// needs, "90.9" on 200 and 100 kHz grids and "90.950" on the 50 kHz one.
+func (s Spacing) FormatMHz(khz uint32) string {
+	frac := strconv.FormatUint(uint64(khz%1000), 10)
+	frac = "00"[:3-len(frac)] + frac
+	if s != Spacing50kHz {
+		frac = frac[:1]
+	}
+	return strconv.FormatUint(uint64(khz/1000), 10) + "." + frac
+}
+
+// SetSpacing sets the channel grid. The tuned channel number is kept, so
+// retune afterwards.
+func (d *Device) SetSpacing(s Spacing) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if s > Spacing50kHz {
+		return ErrInvalidSpacing
+	}
+	return d.modify("spacing", func() {
+		d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2]&^(0x3<<SPACE0) | uint16(s&0x3)<<SPACE0
+	})
+}
+
+// Spacing returns the channel grid. It is taken from the configuration
+// last written, so the bus is not accessed.
+func (d *Device) Spacing() Spacing {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.spacing()
+}
+
+// SnapFrequency returns the frequency in kHz of the channel nearest to
+// khz, the one a tune to khz ends up on.
+func (d *Device) SnapFrequency(khz uint32) uint32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.channelToFrequency(d.frequencyToChannel(khz))
+}
+
+// spacing returns the channel grid from the registers last read.
+func (d *Device) spacing() Spacing {
+	return Spacing(d.registers[SYSCONFIG2] >> SPACE0 & 0x3)
+}
@@ -0,0 +1,135 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidState is returned by RestoreState for data not made by
+// SaveState.
+var ErrInvalidState = errors.New("si4703: invalid saved state")
+
+// stateVersion is the first byte of a saved state.
+const stateVersion = 1
+
+// SaveState returns the writable configuration registers, POWERCFG through
+// TEST1, which hold the band, spacing, volume, seek thresholds and tuned
+// channel. Store it anywhere and pass it to RestoreState to set the tuner
+// up exactly the same way again, for example after deep sleep.
+func (d *Device) SaveState() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return nil, d.registerError("save", err)
+	}
+	return d.saveState(), nil
+}
+
+func (d *Device) saveState() []byte {
+	state := make([]byte, 1, 1+2*(TEST1-POWERCFG+1))
+	state[0] = stateVersion
+	for r := POWERCFG; r <= TEST1; r++ {
+		state = binary.BigEndian.AppendUint16(state, d.registers[r])
+	}
+	return state
+}
+
+// RestoreState writes back registers saved by SaveState in one
+// transaction and retunes to the saved channel. The chip must be powered
+// up.
+func (d *Device) RestoreState(state []byte) error {
+	if !validState(state) {
+		return ErrInvalidState
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return d.registerError("restore", err)
+	}
+	if err := d.restoreState(state); err != nil {
+		return d.registerError("restore", err)
+	}
+	return nil
+}
+
+// LastStationKey is the key SaveLastStation stores under.
+const LastStationKey = "last_station"
+
+// SaveLastStation stores the state, including the tuned channel and
+// volume, in storage for RestoreLastStation after the next power up. With
+// a BlockStorage on flash, call it when the listener settles on a station
+// rather than on every step of the dial; BlockStorage skips rewriting
+// unchanged state.
+func (d *Device) SaveLastStation(storage Storage) error {
+	state, err := d.SaveState()
+	if err != nil {
+		return err
+	}
+	return storage.Store(LastStationKey, state)
+}
+
+// RestoreLastStation restores the state saved by SaveLastStation. It does
+// nothing if none was saved.
+func (d *Device) RestoreLastStation(storage Storage) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.resume(storage)
+}
+
+// resume restores the last station saved in storage, if any.
+func (d *Device) resume(storage Storage) error {
+	state, err := storage.Load(LastStationKey)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !validState(state) {
+		return ErrInvalidState
+	}
+	if err := d.readRegisters(); err != nil {
+		return d.registerError("restore", err)
+	}
+	if err := d.restoreState(state); err != nil {
+		return d.registerError("restore", err)
+	}
+	return nil
+}
+
+func validState(state []byte) bool {
+	return len(state) == 1+2*int(TEST1-POWERCFG+1) && state[0] == stateVersion
+}
+
+// restoreState writes a validated state and tunes to its channel.
+func (d *Device) restoreState(state []byte) error {
+	for r := POWERCFG; r <= TEST1; r++ {
+		i := 1 + 2*(r-POWERCFG)
+		d.registers[r] = binary.BigEndian.Uint16(state[i:])
+	}
+	// never start a seek or power down, but do tune to the saved channel
+	d.registers[POWERCFG] = d.registers[POWERCFG] &^ (1<<SEEK | 1<<DISABLE)
+	// of TEST1 only the oscillator enable is ours to write, the reserved
+	// bits must stay 0x0100
+	d.registers[TEST1] = d.registers[TEST1]&(1<<XOSCEN) | 0x0100
+	d.registers[CHANNEL] = d.registers[CHANNEL] | (1 << TUNE)
+
+	d.count(MetricTunes)
+	d.preTune()
+	err := d.updateRegisters()
+	if err == nil {
+		err = d.finishTune(context.Background())
+	}
+	d.onMute(d.registers[POWERCFG]&(1<<DMUTE) == 0)
+	d.postTune()
+	return err
+}
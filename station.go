@@ -0,0 +1,93 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "time"
+
+// StationInfo is a consistent snapshot of everything known about the tuned
+// station.
+type StationInfo struct {
+	Frequency           uint32 // kHz
+	PI                  uint16
+	Callsign            string // RBDS call letters, see Device.Callsign
+	ProgramService      string
+	ProgramType         uint8
+	RadioText           string
+	TrafficProgram      bool
+	TrafficAnnouncement bool
+	RSSI                uint8 // dBµV
+	Stereo              bool
+	RDSSynchronized     bool
+	RDSQuality          uint8 // percent of the nominal RDS group rate received
+	ECC                 uint8
+	Country             string // ISO 3166 code, "" until the ECC is received
+	RegionMismatch      bool   // de-emphasis or band does not match Country
+}
+
+// CurrentStation reads the registers and combines the tuning status with
+// the decoded RDS information, all under the device lock.
+func (d *Device) CurrentStation() (StationInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return StationInfo{}, d.registerError("station", err)
+	}
+	status := d.registers[STATUSRSSI]
+	data := d.decoder.data()
+	country := Country(data.ECC, data.PI)
+	return StationInfo{
+		Frequency:           d.channelToFrequency(d.registers[READCHAN] & 0x1FF),
+		PI:                  data.PI,
+		Callsign:            d.callsign(),
+		ProgramService:      data.ProgramService,
+		ProgramType:         data.ProgramType,
+		RadioText:           data.RadioText,
+		TrafficProgram:      data.TrafficProgram,
+		TrafficAnnouncement: data.TrafficAnnouncement,
+		RSSI:                d.rssi(status),
+		Stereo:              status>>STEREO&0x1 == 1,
+		RDSSynchronized:     status>>RDSS&0x1 == 1,
+		RDSQuality:          d.rdsQuality.percent(time.Now()),
+		ECC:                 data.ECC,
+		Country:             country,
+		RegionMismatch:      d.regionMismatch(country),
+	}, nil
+}
+
+// rdsGroupsPerSecond is the nominal group rate at 1187.5 bit/s.
+const rdsGroupsPerSecond = 1187.5 / 104
+
+// rdsQualityWindow is how far back received groups are counted.
+const rdsQualityWindow = 2 * time.Second
+
+// rdsQualityMeter remembers when the most recent groups arrived.
+type rdsQualityMeter struct {
+	times [24]time.Time
+	next  int
+}
+
+func (q *rdsQualityMeter) add(now time.Time) {
+	q.times[q.next] = now
+	q.next = (q.next + 1) % len(q.times)
+}
+
+func (q *rdsQualityMeter) percent(now time.Time) uint8 {
+	n := 0
+	for _, t := range q.times {
+		if !t.IsZero() && now.Sub(t) < rdsQualityWindow {
+			n++
+		}
+	}
+	p := float64(n) / (rdsQualityWindow.Seconds() * rdsGroupsPerSecond) * 100
+	if p > 100 {
+		p = 100
+	}
+	return uint8(p + 0.5)
+}
@@ -0,0 +1,212 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package stationdb accumulates what is known about the stations a tuner
+// has received and exchanges it with other devices or a PC as JSON.
+//
+// The exchange format is a single object:
+//
+//	{
+//	  "version": 1,
+//	  "stations": [
+//	    {
+//	      "frequency": 90900,        // kHz
+//	      "pi": "C201",              // RDS PI code, 4 hex digits
+//	      "ps": "BBC R4",            // programme service name, optional
+//	      "pty": 16,                 // RDS programme type, optional
+//	      "afs": [92100, 93500]      // alternative frequencies in kHz, optional
+//	    }
+//	  ]
+//	}
+//
+// A station is identified by its frequency and PI code. Unknown fields are
+// ignored on import so newer files can be read by older firmware.
+package stationdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// Version is the version of the exchange format written by Export.
+const Version = 1
+
+// StorageKey is the key the database is stored under.
+const StorageKey = "stationdb"
+
+// Station is the fingerprint of one transmitter.
+type Station struct {
+	Frequency uint32 // kHz
+	PI        uint16
+	PS        string
+	PTY       uint8
+	AFs       []uint32 // kHz
+}
+
+type jsonStation struct {
+	Frequency uint32   `json:"frequency"`
+	PI        string   `json:"pi"`
+	PS        string   `json:"ps,omitempty"`
+	PTY       uint8    `json:"pty,omitempty"`
+	AFs       []uint32 `json:"afs,omitempty"`
+}
+
+type jsonDB struct {
+	Version  int           `json:"version"`
+	Stations []jsonStation `json:"stations"`
+}
+
+type key struct {
+	freq uint32
+	pi   uint16
+}
+
+// DB is a collection of station fingerprints.
+type DB struct {
+	stations map[key]*Station
+}
+
+func New() *DB {
+	return &DB{
+		stations: make(map[key]*Station),
+	}
+}
+
+// Observe records the RDS information received on the current station.
+func (db *DB) Observe(status si4703.Status, data si4703.RDSData) {
+	if data.PI == 0 {
+		return
+	}
+	s := db.station(status.Frequency, data.PI)
+	if data.ProgramService != "" {
+		s.PS = data.ProgramService
+	}
+	s.PTY = data.ProgramType
+}
+
+// AddAF records an alternative frequency for a station.
+func (db *DB) AddAF(freq uint32, pi uint16, af uint32) {
+	s := db.station(freq, pi)
+	for _, f := range s.AFs {
+		if f == af {
+			return
+		}
+	}
+	s.AFs = append(s.AFs, af)
+}
+
+func (db *DB) station(freq uint32, pi uint16) *Station {
+	k := key{freq, pi}
+	s, ok := db.stations[k]
+	if !ok {
+		s = &Station{Frequency: freq, PI: pi}
+		db.stations[k] = s
+	}
+	return s
+}
+
+// Stations returns all stations ordered by frequency.
+func (db *DB) Stations() []Station {
+	rv := make([]Station, 0, len(db.stations))
+	for _, s := range db.stations {
+		st := *s
+		st.AFs = append([]uint32(nil), s.AFs...)
+		rv = append(rv, st)
+	}
+	sort.Slice(rv, func(i, j int) bool {
+		if rv[i].Frequency != rv[j].Frequency {
+			return rv[i].Frequency < rv[j].Frequency
+		}
+		return rv[i].PI < rv[j].PI
+	})
+	return rv
+}
+
+// Lookup returns the stations known on a frequency.
+func (db *DB) Lookup(freq uint32) []Station {
+	var rv []Station
+	for _, s := range db.Stations() {
+		if s.Frequency == freq {
+			rv = append(rv, s)
+		}
+	}
+	return rv
+}
+
+// Export writes the database in the exchange format.
+func (db *DB) Export(w io.Writer) error {
+	out := jsonDB{Version: Version}
+	for _, s := range db.Stations() {
+		out.Stations = append(out.Stations, jsonStation{
+			Frequency: s.Frequency,
+			PI:        si4703.FormatPI(s.PI),
+			PS:        s.PS,
+			PTY:       s.PTY,
+			AFs:       s.AFs,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// Import merges stations read in the exchange format into the database.
+// Imported values replace what was observed locally, AF lists are merged.
+func (db *DB) Import(r io.Reader) error {
+	var in jsonDB
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return err
+	}
+	if in.Version > Version {
+		return errors.New("stationdb: unsupported version " + strconv.Itoa(in.Version))
+	}
+	for _, js := range in.Stations {
+		pi, err := strconv.ParseUint(js.PI, 16, 16)
+		if err != nil {
+			return errors.New("stationdb: bad PI code " + js.PI)
+		}
+		s := db.station(js.Frequency, uint16(pi))
+		if js.PS != "" {
+			s.PS = js.PS
+		}
+		if js.PTY != 0 {
+			s.PTY = js.PTY
+		}
+		for _, af := range js.AFs {
+			db.AddAF(js.Frequency, uint16(pi), af)
+		}
+	}
+	return nil
+}
+
+// Save stores the database in storage using the exchange format.
+func (db *DB) Save(storage si4703.Storage) error {
+	var buf bytes.Buffer
+	if err := db.Export(&buf); err != nil {
+		return err
+	}
+	return storage.Store(StorageKey, buf.Bytes())
+}
+
+// Load merges the database previously saved to storage, if any.
+func (db *DB) Load(storage si4703.Storage) error {
+	data, err := storage.Load(StorageKey)
+	if err == si4703.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return db.Import(bytes.NewReader(data))
+}
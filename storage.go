@@ -0,0 +1,39 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "errors"
+
+// ErrNotFound is returned by Storage.Load for keys that were never stored.
+var ErrNotFound = errors.New("si4703: key not found")
+
+// Storage persists small named blobs, such as presets or station logs,
+// across power cycles. Implementations may write to flash, an SD card or a
+// file on a host.
+type Storage interface {
+	Load(key string) ([]byte, error)
+	Store(key string, data []byte) error
+}
+
+// MemoryStorage is a Storage that keeps everything in RAM.
+type MemoryStorage map[string][]byte
+
+func (m MemoryStorage) Load(key string) ([]byte, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (m MemoryStorage) Store(key string, data []byte) error {
+	m[key] = append([]byte(nil), data...)
+	return nil
+}
@@ -0,0 +1,73 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import "context"
+
+// Survey tunes to every channel of the band in turn, muted, and returns
+// the RSSI and stereo indicator of each, lowest frequency first, for
+// plotting band occupancy. Unlike ScanBand it does not seek, so empty
+// channels are included. It then returns to the station it started from.
+func (d *Device) Survey() ([]Station, error) {
+	return d.SurveyContext(context.Background())
+}
+
+// SurveyContext is Survey, giving up once ctx is done, when it returns the
+// channels surveyed so far and ctx.Err().
+func (d *Device) SurveyContext(ctx context.Context) ([]Station, error) {
+	muted, err := d.Muted()
+	if err != nil {
+		return nil, err
+	}
+	start, err := d.Frequency()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.EnableMute(); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	rv, err := d.survey(ctx)
+	if terr := d.setFrequency(context.Background(), start); err == nil {
+		err = terr
+	}
+	d.mu.Unlock()
+	if !muted {
+		if merr := d.DisableMute(); err == nil {
+			err = merr
+		}
+	}
+	return rv, err
+}
+
+func (d *Device) survey(ctx context.Context) ([]Station, error) {
+	last := d.frequencyToChannel(d.band().Top())
+	rv := make([]Station, 0, last+1)
+	for channel := uint16(0); channel <= last; channel++ {
+		khz := d.channelToFrequency(channel)
+		if err := d.startTune(khz); err != nil {
+			return rv, d.registerError("survey", err)
+		}
+		err := d.finishTune(ctx)
+		d.postTune()
+		if err != nil && err == ctx.Err() {
+			return rv, err
+		} else if err != nil {
+			return rv, d.registerError("survey", err)
+		}
+		status := d.registers[STATUSRSSI]
+		rv = append(rv, Station{
+			Frequency: khz,
+			RSSI:      d.rssi(status),
+			Stereo:    status>>STEREO&0x1 == 1,
+		})
+	}
+	return rv, nil
+}
@@ -0,0 +1,71 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"context"
+	"time"
+)
+
+// Suspend saves the tuner state and powers the chip down, for MCUs that
+// sleep between short listening windows. Keep the returned state, in RAM or
+// in retained memory if the MCU loses RAM while sleeping, and pass it to
+// Resume on wake.
+func (d *Device) Suspend() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onMute(true)
+	if err := d.readRegisters(); err != nil {
+		return nil, d.registerError("suspend", err)
+	}
+	state := d.saveState()
+
+	// the datasheet asks for RDS to be off before powering down
+	d.registers[SYSCONFIG1] = d.registers[SYSCONFIG1] &^ (1 << RDS)
+	if err := d.updateRegisters(); err != nil {
+		return nil, d.registerError("suspend", err)
+	}
+	d.registers[POWERCFG] = d.registers[POWERCFG] | (1 << DISABLE) | (1 << ENABLE)
+	if err := d.updateRegisters(); err != nil {
+		return nil, d.registerError("suspend", err)
+	}
+	return state, nil
+}
+
+// Resume powers the chip back up with the state returned by Suspend. If
+// the chip kept its supply while suspended, its oscillator is still
+// enabled and the long reset and oscillator settling are skipped;
+// otherwise the chip goes through the full Configure sequence first.
+func (d *Device) Resume(state []byte) error {
+	if !validState(state) {
+		return ErrInvalidState
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return d.registerError("resume", err)
+	}
+	if d.registers[TEST1]&(1<<XOSCEN) == 0 {
+		if err := d.powerUp(context.Background()); err != nil {
+			return d.registerError("resume", err)
+		}
+	} else {
+		d.registers[POWERCFG] = 1 << ENABLE
+		if err := d.updateRegisters(); err != nil {
+			return d.registerError("resume", err)
+		}
+		// wait max powerup time
+		time.Sleep(d.timings.PowerUp)
+	}
+	if err := d.restoreState(state); err != nil {
+		return d.registerError("resume", err)
+	}
+	return nil
+}
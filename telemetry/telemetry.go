@@ -0,0 +1,177 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package telemetry periodically logs reception quality of the tuned
+// station, for propagation studies and antenna comparisons. Rows are written
+// as CSV with a header line or as newline delimited JSON:
+//
+//	time,frequency,rssi,stereo,rds_sync,rds_quality,ps
+//	2024-05-01T18:00:00Z,90900,41,1,1,97,BBC R4
+//
+//	{"time":"2024-05-01T18:00:00Z","frequency":90900,"rssi":41,"stereo":true,"rds_sync":true,"rds_quality":97,"ps":"BBC R4"}
+//
+// Frequencies are in kHz and RSSI in dBµV. RDS quality is the percentage
+// of the nominal 11.4 groups per second that were received in the interval.
+package telemetry
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+type Format uint8
+
+const (
+	CSV Format = iota
+	NDJSON
+)
+
+const DefaultInterval = 10 * time.Second
+
+// groups per second at the RDS bit rate of 1187.5 bit/s
+const groupsPerSecond = 1187.5 / 104
+
+// Row is one telemetry sample.
+type Row struct {
+	Time       time.Time `json:"time"`
+	Frequency  uint32    `json:"frequency"`
+	RSSI       uint8     `json:"rssi"`
+	Stereo     bool      `json:"stereo"`
+	RDSSync    bool      `json:"rds_sync"`
+	RDSQuality uint8     `json:"rds_quality"`
+	PS         string    `json:"ps"`
+}
+
+// Logger writes telemetry rows to an io.Writer.
+type Logger struct {
+	Interval time.Duration
+
+	dev    *si4703.Device
+	format Format
+	w      io.Writer
+	csv    *csv.Writer
+	header bool
+	groups int
+	since  time.Time
+}
+
+func New(dev *si4703.Device, w io.Writer, format Format) *Logger {
+	l := &Logger{
+		Interval: DefaultInterval,
+		dev:      dev,
+		format:   format,
+		w:        w,
+	}
+	if format == CSV {
+		l.csv = csv.NewWriter(w)
+	}
+	return l
+}
+
+// Run reads RDS continuously, counting received groups, and writes a row
+// every Interval until writing fails.
+func (l *Logger) Run() error {
+	return l.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (l *Logger) RunContext(ctx context.Context) error {
+	l.since = time.Now()
+	next := l.since.Add(l.Interval)
+	for {
+		_, ok, err := l.dev.ReadRDS()
+		if err != nil {
+			return err
+		}
+		if ok {
+			l.groups++
+		}
+		if now := time.Now(); !now.Before(next) {
+			row, err := l.Sample(now)
+			if err != nil {
+				return err
+			}
+			if err := l.Write(row); err != nil {
+				return err
+			}
+			next = next.Add(l.Interval)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(l.dev.Timings().RDSPoll):
+		}
+	}
+}
+
+// Sample takes a row for the time now and restarts the RDS group count.
+func (l *Logger) Sample(now time.Time) (Row, error) {
+	status, err := l.dev.Status()
+	if err != nil {
+		return Row{}, err
+	}
+	quality := 0.0
+	if elapsed := now.Sub(l.since).Seconds(); elapsed > 0 {
+		quality = float64(l.groups) / (elapsed * groupsPerSecond) * 100
+	}
+	if quality > 100 {
+		quality = 100
+	}
+	l.groups = 0
+	l.since = now
+	return Row{
+		Time:       now,
+		Frequency:  status.Frequency,
+		RSSI:       status.RSSI,
+		Stereo:     status.Stereo,
+		RDSSync:    status.RDSSynchronized,
+		RDSQuality: uint8(quality + 0.5),
+		PS:         l.dev.RDSData().ProgramService,
+	}, nil
+}
+
+// Write writes a single row in the logger's format.
+func (l *Logger) Write(r Row) error {
+	if l.format == NDJSON {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = l.w.Write(append(data, '\n'))
+		return err
+	}
+	if !l.header {
+		l.csv.Write([]string{"time", "frequency", "rssi", "stereo", "rds_sync", "rds_quality", "ps"})
+		l.header = true
+	}
+	l.csv.Write([]string{
+		r.Time.UTC().Format(time.RFC3339),
+		strconv.FormatUint(uint64(r.Frequency), 10),
+		strconv.Itoa(int(r.RSSI)),
+		flag(r.Stereo),
+		flag(r.RDSSync),
+		strconv.Itoa(int(r.RDSQuality)),
+		r.PS,
+	})
+	l.csv.Flush()
+	return l.csv.Error()
+}
+
+func flag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,111 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTuneTimeout = errors.New("si4703: timed out waiting for tune to complete")
+	ErrSeekTimeout = errors.New("si4703: timed out waiting for seek to complete")
+
+	// errSTCTimeout is returned by waitSTC when its timeout passes.
+	errSTCTimeout = errors.New("stc timeout")
+)
+
+// stcTimeout maps errSTCTimeout to the error of the operation waiting.
+func stcTimeout(err, opErr error) error {
+	if err == errSTCTimeout {
+		return opErr
+	}
+	return err
+}
+
+// Timings are the delays and intervals the driver uses. The subpackages
+// have their own, such as the debounce of buttons, as fields of their
+// types.
+type Timings struct {
+	// Reset is how long the reset pin is held low, and how long the
+	// chip is then left to come out of reset.
+	Reset time.Duration
+	// OscillatorSettle is the wait after enabling the crystal
+	// oscillator. The datasheet asks for at least 500 ms.
+	OscillatorSettle time.Duration
+	// PowerUp is the wait after enabling the chip, the datasheet's
+	// maximum power up time.
+	PowerUp time.Duration
+	// STCPoll is the pause between status reads while waiting for a
+	// tune or seek to complete, 0 to read continuously.
+	STCPoll time.Duration
+	// TuneTimeout and SeekTimeout bound the wait for a tune or seek to
+	// complete, after which it is cancelled and fails with
+	// ErrTuneTimeout or ErrSeekTimeout. A full band seek at 50 kHz
+	// spacing takes up to about 25 s. Zero waits forever.
+	TuneTimeout time.Duration
+	SeekTimeout time.Duration
+	// STCInterruptFallback bounds the wait for an STC interrupt, so a
+	// pulse missed by the MCU only delays a tune instead of hanging it.
+	STCInterruptFallback time.Duration
+	// RDSPoll is the interval RDS is read at by PollRDS, ScanPreview,
+	// the RDS verifying seeks and the RDS loops of the subpackages.
+	RDSPoll time.Duration
+	// RDSVerify is how long SeekTP and SeekPTY listen to each station
+	// for the RDS deciding whether to stay.
+	RDSVerify time.Duration
+	// SignalSample is the pause between status reads when the signal of
+	// a station is averaged, as the kiosk does before playing it.
+	SignalSample time.Duration
+}
+
+// DefaultTimings returns the timings a new device uses.
+func DefaultTimings() Timings {
+	return Timings{
+		Reset:                time.Second,
+		OscillatorSettle:     500 * time.Millisecond,
+		PowerUp:              110 * time.Millisecond,
+		STCPoll:              0,
+		TuneTimeout:          time.Second,
+		SeekTimeout:          30 * time.Second,
+		STCInterruptFallback: 20 * time.Millisecond,
+		RDSPoll:              40 * time.Millisecond,
+		RDSVerify:            2 * time.Second,
+		SignalSample:         100 * time.Millisecond,
+	}
+}
+
+// SetTimings replaces the timings. Set them before Configure for the power
+// up delays to apply.
+func (d *Device) SetTimings(t Timings) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.timings = t
+}
+
+// sleep waits for duration or until ctx is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	t := time.NewTimer(duration)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Timings returns the timings in use.
+func (d *Device) Timings() Timings {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.timings
+}
@@ -0,0 +1,89 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RDS open data application IDs used by the Traffic Message Channel
+const (
+	tmcAID      = 0xCD46
+	tmcAIDAlpha = 0xCD47
+)
+
+// TMCSink receives the raw Traffic Message Channel groups captured while
+// reading RDS, for decoding by navigation software elsewhere.
+type TMCSink interface {
+	TMCGroup(g RDSGroup) error
+}
+
+// SetTMCSink forwards TMC groups to sink, or stops forwarding if sink is
+// nil. TMC is carried in group 8A unless the station announces another
+// group type in its 3A open data application list.
+func (d *Device) SetTMCSink(sink TMCSink) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tmcSink = sink
+}
+
+// tmcTracker follows which group type carries TMC on the tuned station.
+type tmcTracker struct {
+	// group type code as in block B bits 15-11, 0 means not announced
+	code uint16
+}
+
+const tmcDefaultCode = 8<<1 | 0 // 8A
+
+// isTMC reports whether g carries TMC data, updating the group type when g
+// is a 3A announcement.
+func (t *tmcTracker) isTMC(g RDSGroup) bool {
+	code := g.B >> 11
+	if code == 3<<1 && (g.D == tmcAID || g.D == tmcAIDAlpha) {
+		if app := g.B & 0x1F; app != 0 {
+			t.code = app
+		}
+		return true
+	}
+	if t.code == 0 {
+		return code == tmcDefaultCode
+	}
+	return code == t.code
+}
+
+// WriterSink forwards TMC groups as lines of four hexadecimal blocks, the
+// format used by RDS Spy and most RDS tools, to any io.Writer such as a
+// UART or a TCP connection.
+type WriterSink struct {
+	W io.Writer
+}
+
+func (s WriterSink) TMCGroup(g RDSGroup) error {
+	_, err := io.WriteString(s.W, g.String()+"\r\n")
+	return err
+}
+
+// String formats the group as four hexadecimal blocks.
+func (g RDSGroup) String() string {
+	return hex4(g.A) + " " + hex4(g.B) + " " + hex4(g.C) + " " + hex4(g.D)
+}
+
+// FormatPI formats a PI code the way it is usually written, as 4 upper
+// case hexadecimal digits such as "C201".
+func FormatPI(pi uint16) string {
+	return hex4(pi)
+}
+
+func hex4(v uint16) string {
+	h := strings.ToUpper(strconv.FormatUint(uint64(v), 16))
+	return strings.Repeat("0", 4-len(h)) + h
+}
@@ -0,0 +1,37 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// OnTrafficAnnouncement makes the device call f with true when the tuned
+// station starts a traffic announcement and with false when it ends, or
+// when the device tunes away during one. An announcement is on air while
+// the station sets both its TP and TA flags; TA alone only says that
+// another programme it cross-references carries traffic information. f is
+// called with the device locked, so it must not call back into the device.
+// Pass nil to stop.
+func (d *Device) OnTrafficAnnouncement(f func(active bool)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onTrafficAnnouncement = f
+}
+
+// trafficAnnouncement reports whether the station is in a traffic
+// announcement.
+func (r *rdsDecoder) trafficAnnouncement() bool {
+	return r.tp && r.ta
+}
+
+// trafficChanged calls the OnTrafficAnnouncement callback if the
+// announcement state differs from was.
+func (d *Device) trafficChanged(was bool) {
+	if now := d.decoder.trafficAnnouncement(); now != was && d.onTrafficAnnouncement != nil {
+		d.onTrafficAnnouncement(now)
+	}
+}
@@ -7,6 +7,8 @@
 //  either express or implied. See the License for the specific language governing permissions
 //  and limitations under the License.
 
+//go:build tinygo
+
 package main
 
 import (
@@ -19,10 +21,8 @@ import (
 func main() {
 	machine.I2C0.Configure(machine.I2CConfig{})
 	fm := si4703.New(machine.I2C0)
-	fm.Configure()
-	val := 90.9 * 10
-	freqint := uint16(val)
-	fm.SetChannel(freqint)
+	fm.Configure(si4703.Config{})
+	fm.SetFrequency(90900)
 	fm.DisableMute()
 	fm.SetVolume(uint16(8))
 	fm.PollRDS()
@@ -0,0 +1,177 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package voltrim evens out the loudness of different broadcasters. It
+// remembers a volume trim for each station, keyed by PI code or by
+// frequency for stations without RDS, and applies it on top of the master
+// volume whenever that station is tuned. Trims are kept in a Storage so
+// they survive power cycles.
+//
+//	t := voltrim.New(&fm, storage)
+//	t.SetMaster(22)
+//	t.SetTrim(-2) // this station is loud
+//	t.Run()
+package voltrim
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+// StorageKey is the key the trims are stored under.
+const StorageKey = "voltrim"
+
+// Trimmer applies per-station volume trims to one device.
+type Trimmer struct {
+	dev     *si4703.Device
+	storage si4703.Storage
+	master  uint8
+	trims   map[string]int8
+	// station the volume was last set for
+	frequency uint32
+	pi        uint16
+}
+
+// New returns a trimmer with the trims found in storage, if any, and the
+// device's current volume as master volume, or 0 if it cannot be read.
+func New(dev *si4703.Device, storage si4703.Storage) *Trimmer {
+	master, _ := dev.VolumeExtended()
+	t := &Trimmer{
+		dev:     dev,
+		storage: storage,
+		master:  master,
+		trims:   make(map[string]int8),
+	}
+	if data, err := storage.Load(StorageKey); err == nil {
+		json.Unmarshal(data, &t.trims)
+	}
+	return t
+}
+
+// SetMaster sets the master volume, 0 to 30 on the extended scale of
+// si4703.Device.SetVolumeExtended, and applies it with the trim of the
+// tuned station.
+func (t *Trimmer) SetMaster(volume uint8) error {
+	t.master = volume
+	return t.apply()
+}
+
+// Master returns the master volume.
+func (t *Trimmer) Master() uint8 {
+	return t.master
+}
+
+// SetTrim sets the trim of the tuned station in 2 dB steps, applies it
+// and saves it.
+func (t *Trimmer) SetTrim(trim int8) error {
+	k, err := t.key()
+	if err != nil {
+		return err
+	}
+	if trim == 0 {
+		delete(t.trims, k)
+	} else {
+		t.trims[k] = trim
+	}
+	if err := t.apply(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(t.trims)
+	if err != nil {
+		return err
+	}
+	return t.storage.Store(StorageKey, data)
+}
+
+// Trim returns the trim of the tuned station.
+func (t *Trimmer) Trim() (int8, error) {
+	k, err := t.key()
+	return t.trims[k], err
+}
+
+// Run reads RDS and checks for station changes after every group until
+// the device fails.
+func (t *Trimmer) Run() error {
+	return t.RunContext(context.Background())
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (t *Trimmer) RunContext(ctx context.Context) error {
+	for {
+		if _, _, err := t.dev.ReadRDS(); err != nil {
+			return err
+		}
+		if err := t.Check(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.dev.Timings().RDSPoll):
+		}
+	}
+}
+
+// Check applies the trim when the tuned station changed, or when its PI
+// code has been received. Use it instead of Run when the application reads
+// RDS itself.
+func (t *Trimmer) Check() error {
+	status, err := t.dev.Status()
+	if err != nil {
+		return err
+	}
+	pi := t.dev.RDSData().PI
+	if status.Frequency == t.frequency && pi == t.pi {
+		return nil
+	}
+	return t.apply()
+}
+
+// key identifies the tuned station and remembers it as the one the volume
+// is set for.
+func (t *Trimmer) key() (string, error) {
+	status, err := t.dev.Status()
+	if err != nil {
+		return "", err
+	}
+	t.frequency = status.Frequency
+	t.pi = t.dev.RDSData().PI
+	if t.pi != 0 {
+		return "pi:" + si4703.FormatPI(t.pi), nil
+	}
+	return "freq:" + strconv.FormatUint(uint64(t.frequency), 10), nil
+}
+
+func (t *Trimmer) apply() error {
+	k, err := t.key()
+	if err != nil {
+		return err
+	}
+	v := int(t.master) + int(t.trims[k])
+	if t.master == 0 || v < 1 {
+		// a trim never mutes a station, only the master volume does
+		v = int(t.master)
+		if v > 1 {
+			v = 1
+		}
+	}
+	if v > 30 {
+		v = 30
+	}
+	err = t.dev.SetVolumeExtended(uint8(v))
+	if err == si4703.ErrNotSupported {
+		// chips without VOLEXT have no steps below 16
+		err = t.dev.SetVolumeExtended(16)
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package si4703
+
+// volumeSteps is the number of audible steps when VOLUME 1-15 is combined
+// with the VOLEXT 30 dB attenuation, 2 dB apart from -58 dBFS to 0 dBFS.
+const volumeSteps = 30
+
+// SetVolumePercent sets the volume from 0 (muted) to 100 (full scale).
+//
+// The chip's volume steps are 2 dB apart, and loudness is perceived roughly
+// in proportion to dB, so the percentage is spread evenly over all 30 steps
+// of the extended range. That gives a slider that fades in gradually rather
+// than the plain 0-15 scale, whose lowest steps are already fairly loud.
+func (d *Device) SetVolumePercent(p uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if p > 100 {
+		p = 100
+	}
+	step := (uint16(p)*volumeSteps + 99) / 100
+	if d.hasQuirk(quirkNoVolumeExt) {
+		// only the upper half of the range is available
+		step = (uint16(p)*volumeSteps/2+99)/100 + volumeSteps/2
+		if p == 0 {
+			step = 0
+		}
+	}
+
+	return d.modify("volume", func() {
+		d.setVolumeStep(step)
+	})
+}
+
+// VolumePercent returns the volume on the scale used by SetVolumePercent.
+func (d *Device) VolumePercent() (uint8, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return 0, d.registerError("read volume", err)
+	}
+	return uint8(d.volumeStep() * 100 / volumeSteps), nil
+}
+
+// SetVolumeExtended sets the volume on the extended scale from 0 (muted)
+// to 30 (full scale) in 2 dB steps. Steps 1 to 15 use the VOLEXT
+// attenuation for quiet listening on headphones, 16 to 30 are volumes 1 to
+// 15 of SetVolume. Chips before Rev C only have the upper half.
+func (d *Device) SetVolumeExtended(step uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if step > volumeSteps {
+		step = volumeSteps
+	}
+	if d.hasQuirk(quirkNoVolumeExt) && step > 0 && step <= volumeSteps/2 {
+		return ErrNotSupported
+	}
+	return d.modify("volume", func() {
+		d.setVolumeStep(uint16(step))
+	})
+}
+
+// VolumeExtended returns the volume on the scale used by
+// SetVolumeExtended.
+func (d *Device) VolumeExtended() (uint8, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.readRegisters(); err != nil {
+		return 0, d.registerError("read volume", err)
+	}
+	return uint8(d.volumeStep()), nil
+}
+
+// setVolumeStep sets VOLUME and VOLEXT in the shadow registers for a step
+// of the extended scale.
+func (d *Device) setVolumeStep(step uint16) {
+	volume := step
+	if step > 0 && step <= volumeSteps/2 {
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3] | (1 << VOLEXT)
+	} else {
+		d.registers[SYSCONFIG3] = d.registers[SYSCONFIG3] &^ (1 << VOLEXT)
+		if step > 0 {
+			volume = step - volumeSteps/2
+		}
+	}
+	d.registers[SYSCONFIG2] = d.registers[SYSCONFIG2]&0xFFF0 | volume
+}
+
+// volumeStep returns the step of the extended scale from the registers
+// last read.
+func (d *Device) volumeStep() uint16 {
+	step := d.registers[SYSCONFIG2] & 0x000F
+	if step > 0 && d.registers[SYSCONFIG3]&(1<<VOLEXT) == 0 {
+		step += volumeSteps / 2
+	}
+	return step
+}
@@ -0,0 +1,168 @@
+//  Copyright (c) Marty Schoch
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package webhook POSTs a JSON document to a URL whenever the tuned station
+// or its now playing information changes, for home automation and logging
+// services. The body looks like
+//
+//	{
+//	  "event": "now_playing",
+//	  "time": "2024-05-01T18:03:12Z",
+//	  "frequency": 90900,
+//	  "pi": "C201",
+//	  "ps": "BBC R4",
+//	  "artist": "Artist",
+//	  "title": "Title"
+//	}
+//
+// where event is "station" when the frequency or PI code changed and
+// "now_playing" when the RadioText Plus artist or title changed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/mcilley/go-si4703"
+)
+
+const (
+	EventStation    = "station"
+	EventNowPlaying = "now_playing"
+)
+
+// Payload is the JSON document sent to the webhook.
+type Payload struct {
+	Event     string    `json:"event"`
+	Time      time.Time `json:"time"`
+	Frequency uint32    `json:"frequency"`
+	PI        string    `json:"pi,omitempty"`
+	PS        string    `json:"ps,omitempty"`
+	Artist    string    `json:"artist,omitempty"`
+	Title     string    `json:"title,omitempty"`
+}
+
+// Notifier watches a device and calls the webhook on changes.
+type Notifier struct {
+	URL    string
+	Client *http.Client
+	// OnError, if set, is called by Run with each failed post. The
+	// change is posted again at the next check.
+	OnError func(error)
+
+	dev       *si4703.Device
+	frequency uint32
+	pi        uint16
+	artist    string
+	title     string
+}
+
+func New(dev *si4703.Device, url string) *Notifier {
+	return &Notifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+		dev:    dev,
+	}
+}
+
+// Run reads RDS continuously and checks for changes every interval until
+// the device fails. Failed posts are passed to OnError.
+func (n *Notifier) Run(interval time.Duration) error {
+	return n.RunContext(context.Background(), interval)
+}
+
+// RunContext is Run until ctx is done, when it returns ctx.Err().
+func (n *Notifier) RunContext(ctx context.Context, interval time.Duration) error {
+	next := time.Now()
+	for {
+		if _, _, err := n.dev.ReadRDS(); err != nil {
+			return err
+		}
+		if now := time.Now(); !now.Before(next) {
+			var re *si4703.RegisterError
+			if err := n.Check(now); errors.As(err, &re) {
+				return err
+			} else if err != nil && n.OnError != nil {
+				n.OnError(err)
+			}
+			next = now.Add(interval)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(n.dev.Timings().RDSPoll):
+		}
+	}
+}
+
+// Check compares the current station with the last one reported and posts
+// the webhook if it changed. The station only counts as reported once the
+// post succeeded.
+func (n *Notifier) Check(now time.Time) error {
+	status, err := n.dev.Status()
+	if err != nil {
+		return err
+	}
+	data := n.dev.RDSData()
+
+	var event string
+	switch {
+	case status.Frequency != n.frequency || data.PI != n.pi:
+		event = EventStation
+	case data.Artist != n.artist || data.Title != n.title:
+		if data.Artist == "" && data.Title == "" {
+			// tags of the next item not received yet
+			return nil
+		}
+		event = EventNowPlaying
+	default:
+		return nil
+	}
+
+	p := Payload{
+		Event:     event,
+		Time:      now.UTC(),
+		Frequency: status.Frequency,
+		PS:        data.ProgramService,
+		Artist:    data.Artist,
+		Title:     data.Title,
+	}
+	if data.PI != 0 {
+		p.PI = si4703.FormatPI(data.PI)
+	}
+	if err := n.Post(p); err != nil {
+		return err
+	}
+	n.frequency = status.Frequency
+	n.pi = data.PI
+	n.artist = data.Artist
+	n.title = data.Title
+	return nil
+}
+
+// Post sends a payload to the webhook.
+func (n *Notifier) Post(p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return errors.New("webhook: " + resp.Status)
+	}
+	return nil
+}